@@ -20,6 +20,8 @@ import (
 type Server struct {
 	logger      *slog.Logger
 	specStore   storage.SpecStore
+	specWriter  storage.SpecWriter // Optional, enables spec management with adminToken
+	adminToken  string
 	proxyClient *proxy.Client
 }
 
@@ -34,18 +36,28 @@ func main() {
 	}
 
 	// Initialize spec store
-	specStore, err := storage.NewFileSpecStore(cfg.SpecsDir)
+	specStore, err := storage.NewFileSpecStore(cfg.SpecsDir, storage.WithValidationMode(storage.ValidationMode(cfg.SpecValidation)))
 	if err != nil {
 		logger.Error("spec store init failed", "error", err)
 		os.Exit(1)
 	}
 
 	// Initialize proxy client
-	proxyClient := proxy.NewClient(specStore)
+	proxyClient := proxy.NewClient(specStore,
+		proxy.WithRequestValidation(cfg.ValidateRequests),
+		proxy.WithMaxTimeout(cfg.MaxProxyTimeout),
+		proxy.WithDeniedNetworks(cfg.DeniedNetworks...),
+		proxy.WithMaxBodySize(cfg.MaxProxyBodySize),
+		proxy.WithMaxRequestBodySize(cfg.MaxProxyRequestSize),
+		proxy.WithWebSocketLimits(cfg.WebSocketIdleTimeout, cfg.WebSocketMaxDuration),
+		proxy.WithLogger(logger),
+	)
 
 	server := &Server{
 		logger:      logger,
 		specStore:   specStore,
+		specWriter:  specStore,
+		adminToken:  cfg.AdminToken,
 		proxyClient: proxyClient,
 	}
 
@@ -59,6 +71,11 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Pick up spec edits without a restart
+	if cfg.ReloadInterval > 0 {
+		go specStore.Watch(ctx, cfg.ReloadInterval, logger)
+	}
+
 	ln, err := net.Listen("tcp", srv.Addr)
 	if err != nil {
 		logger.Error("listen failed", "error", err)
@@ -97,10 +114,27 @@ func (s *Server) routes() http.Handler {
 	specsHandler := handlers.NewSpecsHandler(s.logger, s.specStore)
 	mux.HandleFunc("GET /api/specs", specsHandler.List)
 	mux.HandleFunc("GET /api/specs/{service}", specsHandler.Get)
+	mux.HandleFunc("GET /api/specs/{service}/diagnostics", specsHandler.Diagnostics)
+	mux.HandleFunc("GET /api/specs/{service}/environments", specsHandler.Environments)
+
+	// Spec management endpoints, only when an admin token is configured
+	if s.specWriter != nil && s.adminToken != "" {
+		adminHandler := handlers.NewSpecsAdminHandler(s.logger, s.specWriter, s.adminToken)
+		mux.HandleFunc("POST /api/specs", adminHandler.Create)
+		mux.HandleFunc("PUT /api/specs/{service}", adminHandler.Replace)
+		mux.HandleFunc("DELETE /api/specs/{service}", adminHandler.Delete)
+	}
 
 	// Proxy endpoint
 	proxyHandler := handlers.NewProxyHandler(s.logger, s.proxyClient)
 	mux.HandleFunc("POST /api/proxy", proxyHandler.Handle)
+	mux.HandleFunc("POST /api/proxy/stream", proxyHandler.Stream)
+
+	// Raw pass-through for curl, Postman and tests; any method is forwarded as it is
+	mux.HandleFunc("/proxy/{service}/{path...}", proxyHandler.Pass)
+
+	// WebSocket relay for services with ws endpoints
+	mux.HandleFunc("GET /ws/{service}/{path...}", proxyHandler.WebSocket)
 
 	return s.cors(s.logging(mux))
 }
@@ -122,8 +156,8 @@ func (s *Server) cors(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 		w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
 
-		// Handle preflight requests
-		if r.Method == http.MethodOptions {
+		// Handle preflight requests; other OPTIONS requests may be meant for a backend via /proxy/
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
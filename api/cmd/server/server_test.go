@@ -250,6 +250,165 @@ func TestServer_Proxy(t *testing.T) {
 	}
 }
 
+func TestServer_PassThrough(t *testing.T) {
+	// Backend echoes what reached it
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Upstream", "yes")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"method":         r.Method,
+			"uri":            r.URL.RequestURI(),
+			"body":           string(body),
+			"contentType":    r.Header.Get("Content-Type"),
+			"apiKey":         r.Header.Get("X-Api-Key"),
+			"custom":         r.Header.Get("X-Custom"),
+			"hop":            r.Header.Get("X-Hop"),
+			"forwardedFor":   r.Header.Get("X-Forwarded-For"),
+			"forwardedHost":  r.Header.Get("X-Forwarded-Host"),
+			"forwardedProto": r.Header.Get("X-Forwarded-Proto"),
+		})
+	}))
+	defer backend.Close()
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	specDir := filepath.Join(t.TempDir(), "specs")
+	os.Mkdir(specDir, 0755)
+
+	testSpec := map[string]interface{}{
+		"openapi": "3.0.0",
+		"x-proxy-config": map[string]interface{}{
+			"baseURL":     backend.URL + "/v1",
+			"authHeaders": map[string]string{"X-Api-Key": "secret"},
+		},
+		"info": map[string]interface{}{"title": "Test API"},
+	}
+	specData, _ := json.Marshal(testSpec)
+	os.WriteFile(filepath.Join(specDir, "test-service.json"), specData, 0644)
+
+	specStore, _ := storage.NewFileSpecStore(specDir)
+	server := &Server{
+		logger:      logger,
+		specStore:   specStore,
+		proxyClient: proxy.NewClient(specStore),
+	}
+
+	ts := httptest.NewServer(server.routes())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPatch, ts.URL+"/proxy/test-service/pets/a%2Fb?tag=x&tag=y", strings.NewReader(`name=rex`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Custom", "kept")
+	req.Header.Set("Connection", "X-Hop")
+	req.Header.Set("X-Hop", "dropped")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected the upstream status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	if resp.Header.Get("X-Upstream") != "yes" {
+		t.Errorf("expected upstream headers to pass through, got %v", resp.Header)
+	}
+
+	var seen map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&seen); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	want := map[string]string{
+		"method":         http.MethodPatch,
+		"uri":            "/v1/pets/a%2Fb?tag=x&tag=y",
+		"body":           "name=rex",
+		"contentType":    "application/x-www-form-urlencoded",
+		"apiKey":         "secret",
+		"custom":         "kept",
+		"hop":            "",
+		"forwardedFor":   "127.0.0.1",
+		"forwardedHost":  strings.TrimPrefix(ts.URL, "http://"),
+		"forwardedProto": "http",
+	}
+	for key, value := range want {
+		if seen[key] != value {
+			t.Errorf("backend saw %s = %q, want %q", key, seen[key], value)
+		}
+	}
+}
+
+func TestServer_PassThrough_BodyTooLarge(t *testing.T) {
+	var reached bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer backend.Close()
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	specDir := filepath.Join(t.TempDir(), "specs")
+	os.Mkdir(specDir, 0755)
+
+	specData, _ := json.Marshal(map[string]interface{}{
+		"openapi":        "3.0.0",
+		"x-proxy-config": map[string]interface{}{"baseURL": backend.URL},
+		"info":           map[string]interface{}{"title": "Test API"},
+	})
+	os.WriteFile(filepath.Join(specDir, "test-service.json"), specData, 0644)
+
+	specStore, _ := storage.NewFileSpecStore(specDir)
+	server := &Server{
+		logger:      logger,
+		specStore:   specStore,
+		proxyClient: proxy.NewClient(specStore, proxy.WithMaxRequestBodySize(8)),
+	}
+
+	ts := httptest.NewServer(server.routes())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/proxy/test-service/upload", "text/plain", strings.NewReader("more than eight bytes"))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, resp.StatusCode)
+	}
+	var p map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if p["code"] != "request_body_too_large" {
+		t.Errorf("expected code request_body_too_large, got %v", p["code"])
+	}
+	if reached {
+		t.Error("expected the oversized body not to reach the backend")
+	}
+}
+
+func TestServer_PassThrough_UnknownService(t *testing.T) {
+	server, _ := setupTestServer(t)
+
+	ts := httptest.NewServer(server.routes())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/proxy/missing/pets")
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected a problem document, got %q", ct)
+	}
+}
+
 func TestServer_Proxy_InvalidJSON(t *testing.T) {
 	server, _ := setupTestServer(t)
 
@@ -339,3 +498,70 @@ func TestServer_CORS_Preflight(t *testing.T) {
 		t.Errorf("expected Access-Control-Max-Age '86400', got %q", resp.Header.Get("Access-Control-Max-Age"))
 	}
 }
+
+func TestServer_SpecManagement(t *testing.T) {
+	server, _ := setupTestServer(t)
+	server.specWriter = server.specStore.(storage.SpecWriter)
+	server.adminToken = "admin-token"
+
+	ts := httptest.NewServer(server.routes())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/api/specs/pets", strings.NewReader("openapi: 3.0.0\ninfo:\n  title: Pets\n"))
+	req.Header.Set("Authorization", "Bearer admin-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/specs/pets")
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var spec map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if info, _ := spec["info"].(map[string]interface{}); info["title"] != "Pets" {
+		t.Errorf("expected uploaded spec to be served, got %v", spec)
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, ts.URL+"/api/specs/pets", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+}
+
+func TestServer_SpecManagement_DisabledWithoutToken(t *testing.T) {
+	server, _ := setupTestServer(t)
+	server.specWriter = server.specStore.(storage.SpecWriter)
+
+	ts := httptest.NewServer(server.routes())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/api/specs/test-service", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
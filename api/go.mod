@@ -1,3 +1,5 @@
 module jonathanmcclement.com/playground
 
 go 1.23
+
+require gopkg.in/yaml.v3 v3.0.1
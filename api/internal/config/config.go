@@ -1,24 +1,142 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Config holds application-level configuration
 type Config struct {
-	SpecsDir string // Path to specs directory
+	SpecsDir             string         // Path to specs directory
+	ReloadInterval       time.Duration  // How often to poll SpecsDir for changes, 0 disables hot reload
+	AdminToken           string         // Bearer token for the spec management API, empty disables it
+	SpecValidation       string         // "warn" loads invalid specs with diagnostics, "strict" rejects them
+	ValidateRequests     bool           // Check every proxied request against its spec operation before forwarding
+	MaxProxyTimeout      time.Duration  // Cap on per-service and per-request proxy timeouts
+	DeniedNetworks       []netip.Prefix // Address ranges the proxy refuses to connect to
+	MaxProxyBodySize     int64          // Bytes of an upstream body buffered into /api/proxy responses before truncating
+	MaxProxyRequestSize  int64          // Bytes of a /proxy/{service} request body accepted before answering 413
+	WebSocketIdleTimeout time.Duration  // Relayed WebSockets close after this long without traffic
+	WebSocketMaxDuration time.Duration  // Relayed WebSockets close this long after opening
 }
 
 // LoadFromEnv loads configuration from environment variables
 // Expected format:
 //
 //	SPECS_DIR=/path/to/specs (defaults to ./data/specs)
+//	SPECS_RELOAD_INTERVAL=2s (Go duration, defaults to 2s, 0 disables)
+//	SPECS_ADMIN_TOKEN=secret (enables POST/PUT/DELETE /api/specs when set)
+//	SPECS_VALIDATION=warn|strict (defaults to warn)
+//	PROXY_VALIDATE_REQUESTS=true (defaults to false; requests can also opt in individually)
+//	PROXY_MAX_TIMEOUT=2m (Go duration, defaults to 2m)
+//	PROXY_DENY_CIDRS=169.254.0.0/16,fe80::/10 (comma-separated CIDRs or IPs, defaults to none)
+//	PROXY_MAX_BODY_SIZE=10485760 (bytes, defaults to 10 MiB)
+//	PROXY_MAX_REQUEST_BODY_SIZE=10485760 (bytes, defaults to 10 MiB)
+//	PROXY_WS_IDLE_TIMEOUT=5m (Go duration, defaults to 5m)
+//	PROXY_WS_MAX_DURATION=1h (Go duration, defaults to 1h)
 func LoadFromEnv() (*Config, error) {
+	reloadInterval, err := time.ParseDuration(getEnvOrDefault("SPECS_RELOAD_INTERVAL", "2s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPECS_RELOAD_INTERVAL: %w", err)
+	}
+
+	specValidation := getEnvOrDefault("SPECS_VALIDATION", "warn")
+	if specValidation != "warn" && specValidation != "strict" {
+		return nil, fmt.Errorf("invalid SPECS_VALIDATION %q: must be warn or strict", specValidation)
+	}
+
+	validateRequests, err := strconv.ParseBool(getEnvOrDefault("PROXY_VALIDATE_REQUESTS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY_VALIDATE_REQUESTS: %w", err)
+	}
+
+	maxProxyTimeout, err := time.ParseDuration(getEnvOrDefault("PROXY_MAX_TIMEOUT", "2m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY_MAX_TIMEOUT: %w", err)
+	}
+	if maxProxyTimeout <= 0 {
+		return nil, fmt.Errorf("invalid PROXY_MAX_TIMEOUT %q: must be positive", maxProxyTimeout)
+	}
+
+	deniedNetworks, err := parsePrefixes(os.Getenv("PROXY_DENY_CIDRS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY_DENY_CIDRS: %w", err)
+	}
+
+	maxProxyBodySize, err := strconv.ParseInt(getEnvOrDefault("PROXY_MAX_BODY_SIZE", "10485760"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY_MAX_BODY_SIZE: %w", err)
+	}
+	if maxProxyBodySize <= 0 {
+		return nil, fmt.Errorf("invalid PROXY_MAX_BODY_SIZE %d: must be positive", maxProxyBodySize)
+	}
+
+	maxProxyRequestSize, err := strconv.ParseInt(getEnvOrDefault("PROXY_MAX_REQUEST_BODY_SIZE", "10485760"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY_MAX_REQUEST_BODY_SIZE: %w", err)
+	}
+	if maxProxyRequestSize <= 0 {
+		return nil, fmt.Errorf("invalid PROXY_MAX_REQUEST_BODY_SIZE %d: must be positive", maxProxyRequestSize)
+	}
+
+	webSocketIdleTimeout, err := time.ParseDuration(getEnvOrDefault("PROXY_WS_IDLE_TIMEOUT", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY_WS_IDLE_TIMEOUT: %w", err)
+	}
+	if webSocketIdleTimeout <= 0 {
+		return nil, fmt.Errorf("invalid PROXY_WS_IDLE_TIMEOUT %q: must be positive", webSocketIdleTimeout)
+	}
+
+	webSocketMaxDuration, err := time.ParseDuration(getEnvOrDefault("PROXY_WS_MAX_DURATION", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY_WS_MAX_DURATION: %w", err)
+	}
+	if webSocketMaxDuration <= 0 {
+		return nil, fmt.Errorf("invalid PROXY_WS_MAX_DURATION %q: must be positive", webSocketMaxDuration)
+	}
+
 	cfg := &Config{
-		SpecsDir: getEnvOrDefault("SPECS_DIR", "./data/specs"),
+		SpecsDir:             getEnvOrDefault("SPECS_DIR", "./data/specs"),
+		ReloadInterval:       reloadInterval,
+		AdminToken:           os.Getenv("SPECS_ADMIN_TOKEN"),
+		SpecValidation:       specValidation,
+		ValidateRequests:     validateRequests,
+		MaxProxyTimeout:      maxProxyTimeout,
+		DeniedNetworks:       deniedNetworks,
+		MaxProxyBodySize:     maxProxyBodySize,
+		MaxProxyRequestSize:  maxProxyRequestSize,
+		WebSocketIdleTimeout: webSocketIdleTimeout,
+		WebSocketMaxDuration: webSocketMaxDuration,
 	}
 
 	return cfg, nil
 }
 
+// parsePrefixes parses a comma-separated list of CIDR ranges; a bare IP is a single-address range
+func parsePrefixes(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(item); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
 // getEnvOrDefault returns environment variable value or default
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestLoadFromEnv_Defaults(t *testing.T) {
 	cfg, err := LoadFromEnv()
@@ -25,3 +28,203 @@ func TestLoadFromEnv_CustomSpecsDir(t *testing.T) {
 		t.Errorf("expected SpecsDir '/custom/path', got %q", cfg.SpecsDir)
 	}
 }
+
+func TestLoadFromEnv_ReloadInterval(t *testing.T) {
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+
+	if cfg.ReloadInterval != 2*time.Second {
+		t.Errorf("expected default ReloadInterval 2s, got %v", cfg.ReloadInterval)
+	}
+
+	t.Setenv("SPECS_RELOAD_INTERVAL", "0")
+
+	cfg, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+
+	if cfg.ReloadInterval != 0 {
+		t.Errorf("expected ReloadInterval 0, got %v", cfg.ReloadInterval)
+	}
+}
+
+func TestLoadFromEnv_InvalidReloadInterval(t *testing.T) {
+	t.Setenv("SPECS_RELOAD_INTERVAL", "soon")
+
+	if _, err := LoadFromEnv(); err == nil {
+		t.Fatal("expected error for invalid SPECS_RELOAD_INTERVAL, got nil")
+	}
+}
+
+func TestLoadFromEnv_SpecValidation(t *testing.T) {
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+
+	if cfg.SpecValidation != "warn" {
+		t.Errorf("expected default SpecValidation 'warn', got %q", cfg.SpecValidation)
+	}
+
+	t.Setenv("SPECS_VALIDATION", "strict")
+
+	cfg, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+
+	if cfg.SpecValidation != "strict" {
+		t.Errorf("expected SpecValidation 'strict', got %q", cfg.SpecValidation)
+	}
+
+	t.Setenv("SPECS_VALIDATION", "lenient")
+
+	if _, err := LoadFromEnv(); err == nil {
+		t.Fatal("expected error for invalid SPECS_VALIDATION, got nil")
+	}
+}
+
+func TestLoadFromEnv_ValidateRequests(t *testing.T) {
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.ValidateRequests {
+		t.Error("expected request validation to be off by default")
+	}
+
+	t.Setenv("PROXY_VALIDATE_REQUESTS", "true")
+	if cfg, err = LoadFromEnv(); err != nil || !cfg.ValidateRequests {
+		t.Errorf("expected ValidateRequests true, got %v (%v)", cfg, err)
+	}
+
+	t.Setenv("PROXY_VALIDATE_REQUESTS", "sometimes")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Fatal("expected error for invalid PROXY_VALIDATE_REQUESTS, got nil")
+	}
+}
+
+func TestLoadFromEnv_MaxProxyTimeout(t *testing.T) {
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.MaxProxyTimeout != 2*time.Minute {
+		t.Errorf("expected default max proxy timeout 2m, got %v", cfg.MaxProxyTimeout)
+	}
+
+	t.Setenv("PROXY_MAX_TIMEOUT", "45s")
+	if cfg, err = LoadFromEnv(); err != nil || cfg.MaxProxyTimeout != 45*time.Second {
+		t.Errorf("expected MaxProxyTimeout 45s, got %v (%v)", cfg, err)
+	}
+
+	for _, value := range []string{"later", "0s"} {
+		t.Setenv("PROXY_MAX_TIMEOUT", value)
+		if _, err := LoadFromEnv(); err == nil {
+			t.Errorf("expected error for PROXY_MAX_TIMEOUT %q, got nil", value)
+		}
+	}
+}
+
+func TestLoadFromEnv_DeniedNetworks(t *testing.T) {
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if len(cfg.DeniedNetworks) != 0 {
+		t.Errorf("expected no denied networks by default, got %v", cfg.DeniedNetworks)
+	}
+
+	t.Setenv("PROXY_DENY_CIDRS", "169.254.0.0/16, fd00:ec2::254 ,10.1.2.3/8")
+	cfg, err = LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	want := []string{"169.254.0.0/16", "fd00:ec2::254/128", "10.0.0.0/8"}
+	if len(cfg.DeniedNetworks) != len(want) {
+		t.Fatalf("expected %v, got %v", want, cfg.DeniedNetworks)
+	}
+	for i, prefix := range cfg.DeniedNetworks {
+		if prefix.String() != want[i] {
+			t.Errorf("expected %s, got %s", want[i], prefix)
+		}
+	}
+
+	t.Setenv("PROXY_DENY_CIDRS", "169.254.0.0/99")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Fatal("expected error for invalid PROXY_DENY_CIDRS, got nil")
+	}
+}
+
+func TestLoadFromEnv_MaxProxyBodySize(t *testing.T) {
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.MaxProxyBodySize != 10<<20 {
+		t.Errorf("expected default MaxProxyBodySize of 10 MiB, got %d", cfg.MaxProxyBodySize)
+	}
+
+	t.Setenv("PROXY_MAX_BODY_SIZE", "1024")
+	if cfg, err = LoadFromEnv(); err != nil || cfg.MaxProxyBodySize != 1024 {
+		t.Errorf("expected MaxProxyBodySize 1024, got %v (%v)", cfg, err)
+	}
+
+	for _, value := range []string{"10MB", "0", "-1"} {
+		t.Setenv("PROXY_MAX_BODY_SIZE", value)
+		if _, err := LoadFromEnv(); err == nil {
+			t.Errorf("expected error for PROXY_MAX_BODY_SIZE %q, got nil", value)
+		}
+	}
+}
+
+func TestLoadFromEnv_MaxProxyRequestSize(t *testing.T) {
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.MaxProxyRequestSize != 10<<20 {
+		t.Errorf("expected default MaxProxyRequestSize of 10 MiB, got %d", cfg.MaxProxyRequestSize)
+	}
+
+	t.Setenv("PROXY_MAX_REQUEST_BODY_SIZE", "1024")
+	if cfg, err = LoadFromEnv(); err != nil || cfg.MaxProxyRequestSize != 1024 {
+		t.Errorf("expected MaxProxyRequestSize 1024, got %v (%v)", cfg, err)
+	}
+
+	for _, value := range []string{"10MB", "0", "-1"} {
+		t.Setenv("PROXY_MAX_REQUEST_BODY_SIZE", value)
+		if _, err := LoadFromEnv(); err == nil {
+			t.Errorf("expected error for PROXY_MAX_REQUEST_BODY_SIZE %q, got nil", value)
+		}
+	}
+}
+
+func TestLoadFromEnv_WebSocketLimits(t *testing.T) {
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatalf("LoadFromEnv() failed: %v", err)
+	}
+	if cfg.WebSocketIdleTimeout != 5*time.Minute || cfg.WebSocketMaxDuration != time.Hour {
+		t.Errorf("expected default limits 5m and 1h, got %v and %v", cfg.WebSocketIdleTimeout, cfg.WebSocketMaxDuration)
+	}
+
+	t.Setenv("PROXY_WS_IDLE_TIMEOUT", "30s")
+	t.Setenv("PROXY_WS_MAX_DURATION", "10m")
+	if cfg, err = LoadFromEnv(); err != nil || cfg.WebSocketIdleTimeout != 30*time.Second || cfg.WebSocketMaxDuration != 10*time.Minute {
+		t.Errorf("expected limits 30s and 10m, got %v (%v)", cfg, err)
+	}
+
+	for _, name := range []string{"PROXY_WS_IDLE_TIMEOUT", "PROXY_WS_MAX_DURATION"} {
+		for _, value := range []string{"forever", "0s"} {
+			t.Setenv(name, value)
+			if _, err := LoadFromEnv(); err == nil {
+				t.Errorf("expected error for %s %q, got nil", name, value)
+			}
+		}
+		t.Setenv(name, "1m")
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"jonathanmcclement.com/playground/internal/proxy"
+)
+
+// problemContentType is the media type of RFC 7807 problem details
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix namespaces the stable error codes used as problem types
+const problemTypePrefix = "urn:playground:problem:"
+
+// Stable error codes, one per failure class
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeBodyTooLarge        = "request_body_too_large"
+	CodeValidationFailed    = "request_validation_failed"
+	CodeServiceNotFound     = "service_not_found"
+	CodeTargetDenied        = "target_denied"
+	CodeTokenRequest        = "token_request_failed"
+	CodeUpstreamTimeout     = "upstream_timeout"
+	CodeCanceled            = "request_canceled"
+	CodeUpstreamDNS         = "upstream_dns_failure"
+	CodeUpstreamRefused     = "upstream_connection_refused"
+	CodeUpstreamUnreachable = "upstream_unreachable"
+	CodeUpstreamResponse    = "upstream_response_unreadable"
+	CodeProxyError          = "proxy_error"
+)
+
+// StatusClientClosedRequest is the non-standard status (from nginx) for requests the client abandoned
+// Nobody is left to read it; it keeps access logs from counting cancellations as upstream failures.
+const StatusClientClosedRequest = 499
+
+// problem is an RFC 7807 problem details body
+// Code repeats the last segment of Type so clients can switch on it without parsing URNs
+type problem struct {
+	Type       string            `json:"type"`
+	Title      string            `json:"title"`
+	Status     int               `json:"status"`
+	Detail     string            `json:"detail,omitempty"`
+	Instance   string            `json:"instance,omitempty"`
+	Code       string            `json:"code"`
+	Violations []proxy.Violation `json:"violations,omitempty"`
+}
+
+// proxyErrorClasses maps Forward's failure classes to a status, code and title, checked in order
+var proxyErrorClasses = []struct {
+	err    error
+	status int
+	code   string
+	title  string
+}{
+	{proxy.ErrServiceNotFound, http.StatusNotFound, CodeServiceNotFound, "Service not found"},
+	{proxy.ErrInvalidRequest, http.StatusBadRequest, CodeInvalidRequest, "Invalid proxy request"},
+	{proxy.ErrTargetDenied, http.StatusForbidden, CodeTargetDenied, "Target address denied"},
+	{proxy.ErrCanceled, StatusClientClosedRequest, CodeCanceled, "Request canceled"},
+	{proxy.ErrTokenRequest, http.StatusBadGateway, CodeTokenRequest, "Access token could not be obtained"},
+	{proxy.ErrUpstreamTimeout, http.StatusGatewayTimeout, CodeUpstreamTimeout, "Upstream timed out"},
+	{proxy.ErrUpstreamDNS, http.StatusBadGateway, CodeUpstreamDNS, "Upstream host not found"},
+	{proxy.ErrUpstreamRefused, http.StatusBadGateway, CodeUpstreamRefused, "Upstream refused the connection"},
+	{proxy.ErrUpstreamUnreachable, http.StatusBadGateway, CodeUpstreamUnreachable, "Upstream unreachable"},
+	{proxy.ErrUpstreamResponse, http.StatusBadGateway, CodeUpstreamResponse, "Upstream response could not be read"},
+}
+
+// proxyProblem builds the problem details for an error returned by Forward
+func proxyProblem(err error) *problem {
+	var validationErr *proxy.ValidationError
+	if errors.As(err, &validationErr) {
+		p := newProblem(http.StatusUnprocessableEntity, CodeValidationFailed, "Request does not match the spec", err.Error())
+		p.Violations = validationErr.Violations
+		return p
+	}
+
+	for _, class := range proxyErrorClasses {
+		if errors.Is(err, class.err) {
+			return newProblem(class.status, class.code, class.title, err.Error())
+		}
+	}
+	return newProblem(http.StatusBadGateway, CodeProxyError, "Proxy request failed", err.Error())
+}
+
+// newProblem creates problem details with a type derived from the code
+func newProblem(status int, code, title, detail string) *problem {
+	return &problem{
+		Type:   problemTypePrefix + code,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// writeProblem writes problem details as an application/problem+json response
+func writeProblem(w http.ResponseWriter, logger *slog.Logger, p *problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		logger.Error("failed to encode response", "error", err)
+	}
+}
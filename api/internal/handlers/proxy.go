@@ -2,8 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 
 	"jonathanmcclement.com/playground/internal/proxy"
 )
@@ -23,20 +28,20 @@ func NewProxyHandler(logger *slog.Logger, proxyClient *proxy.Client) *ProxyHandl
 }
 
 // Handle handles POST /api/proxy
+// Failures are answered with application/problem+json bodies carrying a stable code per failure class
 func (h *ProxyHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	var req proxy.Request
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Warn("invalid request body", "error", err)
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+		writeProblem(w, h.logger, newProblem(http.StatusBadRequest, CodeInvalidRequest, "Invalid proxy request", "invalid request body: "+err.Error()))
 		return
 	}
 
 	h.logger.Info("proxying request", "service", req.Service, "method", req.Method, "path", req.Path)
 
-	resp, err := h.proxyClient.Forward(&req)
+	resp, err := h.proxyClient.Forward(r.Context(), &req)
 	if err != nil {
-		h.logger.Error("proxy failed", "error", err, "service", req.Service, "method", req.Method, "path", req.Path)
-		http.Error(w, "proxy request failed", http.StatusBadGateway)
+		h.writeProxyError(w, &req, err)
 		return
 	}
 
@@ -48,3 +53,155 @@ func (h *ProxyHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		h.logger.Error("failed to encode response", "error", err)
 	}
 }
+
+// Stream handles POST /api/proxy/stream
+// It takes the same request as Handle but answers with the upstream status, headers and body
+// as they arrive, flushing each chunk, so large downloads and Server-Sent Events pass through.
+// Failures before the upstream answers are problem+json; later ones end the response early.
+func (h *ProxyHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	var req proxy.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("invalid request body", "error", err)
+		writeProblem(w, h.logger, newProblem(http.StatusBadRequest, CodeInvalidRequest, "Invalid proxy request", "invalid request body: "+err.Error()))
+		return
+	}
+
+	h.logger.Info("streaming request", "service", req.Service, "method", req.Method, "path", req.Path)
+
+	resp, err := h.proxyClient.Stream(r.Context(), &req)
+	if err != nil {
+		h.writeProxyError(w, &req, err)
+		return
+	}
+	h.copyStream(w, &req, resp)
+}
+
+// Pass handles /proxy/{service}/{path...} for every method
+// The request is forwarded as it is to the service's base URL: method, path, query, headers
+// and body, with the service's auth injected, hop-by-hop headers stripped and X-Forwarded-*
+// set. The upstream answer comes back unwrapped, like Stream's. Bodies over the client's
+// MaxRequestBodySize are answered with 413.
+func (h *ProxyHandler) Pass(w http.ResponseWriter, r *http.Request) {
+	service := r.PathValue("service")
+	path := upstreamPath(r, "/proxy/"+service)
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.proxyClient.MaxRequestBodySize()))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.logger.Warn("pass-through body too large", "service", service, "limit", tooLarge.Limit)
+		writeProblem(w, h.logger, newProblem(http.StatusRequestEntityTooLarge, CodeBodyTooLarge, "Request body too large",
+			fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)))
+		return
+	}
+	if err != nil {
+		h.logger.Warn("failed to read pass-through body", "error", err)
+		writeProblem(w, h.logger, newProblem(http.StatusBadRequest, CodeInvalidRequest, "Invalid proxy request", "failed to read request body: "+err.Error()))
+		return
+	}
+
+	req := proxy.Request{
+		Service: service,
+		Method:  r.Method,
+		Path:    path,
+		Raw:     &proxy.RawRequest{Header: forwardedHeader(r), Body: body},
+	}
+
+	h.logger.Info("passing request through", "service", req.Service, "method", req.Method, "path", req.Path)
+
+	resp, err := h.proxyClient.Stream(r.Context(), &req)
+	if err != nil {
+		h.writeProxyError(w, &req, err)
+		return
+	}
+	h.copyStream(w, &req, resp)
+}
+
+// upstreamPath returns the request's path below prefix, with its query, as sent to the backend
+// The escaped form is kept so encoded slashes and the like reach the backend unchanged
+func upstreamPath(r *http.Request, prefix string) string {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), prefix)
+	if path == "" {
+		path = "/"
+	}
+	if r.URL.RawQuery != "" {
+		path += "?" + r.URL.RawQuery
+	}
+	return path
+}
+
+// forwardedHeader copies an incoming request's headers and records the hop in X-Forwarded-*
+func forwardedHeader(r *http.Request) http.Header {
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	if clientIP, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		if prior := header.Get("X-Forwarded-For"); prior != "" {
+			clientIP = prior + ", " + clientIP
+		}
+		header.Set("X-Forwarded-For", clientIP)
+	}
+	header.Set("X-Forwarded-Host", r.Host)
+	if r.TLS != nil {
+		header.Set("X-Forwarded-Proto", "https")
+	} else {
+		header.Set("X-Forwarded-Proto", "http")
+	}
+	return header
+}
+
+// copyStream writes a streamed upstream response to the client, flushing every chunk
+func (h *ProxyHandler) copyStream(w http.ResponseWriter, req *proxy.Request, resp *proxy.StreamResponse) {
+	defer resp.Body.Close()
+
+	for name, values := range resp.Header {
+		// CORS is decided by this server, not the backend
+		if strings.HasPrefix(strings.ToLower(name), "access-control-") {
+			continue
+		}
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	rc := http.NewResponseController(w)
+	if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return
+	}
+
+	buf := make([]byte, 32<<10)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				h.logger.Warn("stream client went away", "error", err, "service", req.Service)
+				return
+			}
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			h.logger.Error("stream interrupted", "error", readErr, "service", req.Service)
+			return
+		}
+	}
+
+	h.logger.Info("stream finished", "service", req.Service, "status", resp.StatusCode)
+}
+
+// writeProxyError logs a failed proxy call and answers with its problem details
+func (h *ProxyHandler) writeProxyError(w http.ResponseWriter, req *proxy.Request, err error) {
+	p := proxyProblem(err)
+	if p.Status >= http.StatusInternalServerError {
+		h.logger.Error("proxy failed", "error", err, "code", p.Code, "service", req.Service, "method", req.Method, "path", req.Path)
+	} else {
+		h.logger.Warn("proxy request rejected", "error", err, "code", p.Code, "service", req.Service, "method", req.Method, "path", req.Path)
+	}
+	writeProblem(w, h.logger, p)
+}
@@ -6,8 +6,10 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"strings"
 	"testing"
+	"time"
 
 	"jonathanmcclement.com/playground/internal/handlers"
 	"jonathanmcclement.com/playground/internal/proxy"
@@ -99,9 +101,7 @@ func TestProxyHandler_Handle_ServiceNotFound(t *testing.T) {
 
 	handler.Handle(rec, req)
 
-	if rec.Code != http.StatusBadGateway {
-		t.Errorf("expected status %d, got %d", http.StatusBadGateway, rec.Code)
-	}
+	expectProblem(t, rec, http.StatusNotFound, handlers.CodeServiceNotFound)
 }
 
 func TestProxyHandler_Handle_POST_WithBody(t *testing.T) {
@@ -174,7 +174,281 @@ func TestProxyHandler_Handle_InvalidMethod(t *testing.T) {
 
 	handler.Handle(rec, req)
 
-	if rec.Code != http.StatusBadGateway {
-		t.Errorf("expected status %d, got %d", http.StatusBadGateway, rec.Code)
+	expectProblem(t, rec, http.StatusBadRequest, handlers.CodeInvalidRequest)
+}
+
+func TestProxyHandler_Handle_NonJSONResponse(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("<html><body>upstream down</body></html>"))
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {
+				BaseURL: backend.URL,
+			},
+		},
+	}
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	proxyClient := proxy.NewClient(store)
+	handler := handlers.NewProxyHandler(logger, proxyClient)
+
+	reqBody := `{"service":"test-service","method":"GET","path":"/test"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+
+	handler.Handle(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+
+	var resp proxy.Response
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if resp.BodyEncoding != proxy.BodyEncodingText {
+		t.Errorf("expected body encoding %q, got %q", proxy.BodyEncodingText, resp.BodyEncoding)
+	}
+
+	var body string
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("failed to unmarshal text body: %v", err)
+	}
+
+	if body != "<html><body>upstream down</body></html>" {
+		t.Errorf("expected HTML body to round-trip, got %q", body)
+	}
+}
+
+func TestProxyHandler_Handle_ValidationFailed(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("invalid request should not reach the backend")
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		specs: map[string]json.RawMessage{
+			"test-service": json.RawMessage(`{"openapi":"3.0.0","paths":{"/items/{id}":{"get":{
+				"parameters":[{"name":"id","in":"path","required":true,"schema":{"type":"integer"}}]}}}}`),
+		},
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL},
+		},
+	}
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	handler := handlers.NewProxyHandler(logger, proxy.NewClient(store))
+
+	reqBody := `{"service":"test-service","method":"GET","path":"/items/abc","validate":true}`
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+
+	handler.Handle(rec, req)
+
+	resp := expectProblem(t, rec, http.StatusUnprocessableEntity, handlers.CodeValidationFailed)
+	if len(resp.Violations) != 1 || resp.Violations[0].Pointer != "/path/id" {
+		t.Errorf("expected a /path/id violation, got %+v", resp.Violations)
+	}
+}
+
+func TestProxyHandler_Handle_UpstreamRefused(t *testing.T) {
+	// Grab a free port, then close the listener so nothing answers on it
+	backend := httptest.NewServer(http.NotFoundHandler())
+	baseURL := backend.URL
+	backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: baseURL},
+		},
+	}
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	handler := handlers.NewProxyHandler(logger, proxy.NewClient(store))
+
+	reqBody := `{"service":"test-service","method":"GET","path":"/test"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+
+	handler.Handle(rec, req)
+
+	resp := expectProblem(t, rec, http.StatusBadGateway, handlers.CodeUpstreamRefused)
+	if resp.Title == "" || resp.Detail == "" {
+		t.Errorf("expected title and detail to be set, got %+v", resp)
+	}
+}
+
+func TestProxyHandler_Handle_UpstreamTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL, ResponseTimeout: "50ms"},
+		},
+	}
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	handler := handlers.NewProxyHandler(logger, proxy.NewClient(store))
+
+	reqBody := `{"service":"test-service","method":"GET","path":"/slow"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+
+	handler.Handle(rec, req)
+
+	expectProblem(t, rec, http.StatusGatewayTimeout, handlers.CodeUpstreamTimeout)
+}
+
+func TestProxyHandler_Handle_TargetDenied(t *testing.T) {
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"metadata": {BaseURL: "http://169.254.169.254"},
+		},
+	}
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	client := proxy.NewClient(store, proxy.WithDeniedNetworks(netip.MustParsePrefix("169.254.0.0/16")))
+	handler := handlers.NewProxyHandler(logger, client)
+
+	reqBody := `{"service":"metadata","method":"GET","path":"/latest/meta-data/"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+
+	handler.Handle(rec, req)
+
+	expectProblem(t, rec, http.StatusForbidden, handlers.CodeTargetDenied)
+}
+
+func TestProxyHandler_Handle_PathEscapesHost(t *testing.T) {
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: "http://example.com"},
+		},
+	}
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	handler := handlers.NewProxyHandler(logger, proxy.NewClient(store))
+
+	reqBody := `{"service":"test-service","method":"GET","path":"@evil.example/"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+
+	handler.Handle(rec, req)
+
+	expectProblem(t, rec, http.StatusBadRequest, handlers.CodeInvalidRequest)
+}
+
+func TestProxyHandler_Handle_InvalidBodyProblem(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	handler := handlers.NewProxyHandler(logger, proxy.NewClient(&mockSpecStore{}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy", strings.NewReader("{"))
+	rec := httptest.NewRecorder()
+
+	handler.Handle(rec, req)
+
+	expectProblem(t, rec, http.StatusBadRequest, handlers.CodeInvalidRequest)
+}
+
+// problemBody mirrors the problem details the proxy handler writes on failure
+type problemBody struct {
+	Type       string            `json:"type"`
+	Title      string            `json:"title"`
+	Status     int               `json:"status"`
+	Detail     string            `json:"detail"`
+	Code       string            `json:"code"`
+	Violations []proxy.Violation `json:"violations"`
+}
+
+// expectProblem checks that rec holds an application/problem+json body with the given status and code
+func TestProxyHandler_Stream(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Access-Control-Allow-Origin", "https://backend.example")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("data: hello\n\n"))
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL},
+		},
+	}
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	handler := handlers.NewProxyHandler(logger, proxy.NewClient(store))
+
+	reqBody := `{"service":"test-service","method":"GET","path":"/events"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy/stream", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+
+	handler.Stream(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("expected the upstream status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected the upstream Content-Type, got %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected upstream CORS headers to be dropped, got %q", got)
+	}
+	if !rec.Flushed {
+		t.Error("expected the stream to be flushed")
+	}
+	if rec.Body.String() != "data: hello\n\n" {
+		t.Errorf("expected the raw upstream body, got %q", rec.Body.String())
+	}
+}
+
+func TestProxyHandler_Stream_ServiceNotFound(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	handler := handlers.NewProxyHandler(logger, proxy.NewClient(&mockSpecStore{}))
+
+	reqBody := `{"service":"missing","method":"GET","path":"/events"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/proxy/stream", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+
+	handler.Stream(rec, req)
+
+	expectProblem(t, rec, http.StatusNotFound, handlers.CodeServiceNotFound)
+}
+
+func expectProblem(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) problemBody {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("expected status %d, got %d", status, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected Content-Type application/problem+json, got %q", ct)
+	}
+
+	var resp problemBody
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if resp.Status != status {
+		t.Errorf("expected problem status %d, got %d", status, resp.Status)
+	}
+	if resp.Code != code {
+		t.Errorf("expected code %q, got %q", code, resp.Code)
+	}
+	if resp.Type != "urn:playground:problem:"+code {
+		t.Errorf("expected type for code %q, got %q", code, resp.Type)
 	}
+	return resp
 }
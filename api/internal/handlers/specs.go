@@ -38,6 +38,7 @@ func (h *SpecsHandler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 // Get handles GET /api/specs/{service} - returns OpenAPI spec
+// Swagger 2.0 specs are served converted to OpenAPI 3; ?format=original returns them as authored
 func (h *SpecsHandler) Get(w http.ResponseWriter, r *http.Request) {
 	serviceName := r.PathValue("service")
 	if serviceName == "" {
@@ -45,7 +46,17 @@ func (h *SpecsHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	spec, err := h.store.Get(serviceName)
+	get := h.store.Get
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+	case "original":
+		get = h.store.GetOriginal
+	default:
+		http.Error(w, "unsupported format: "+format, http.StatusBadRequest)
+		return
+	}
+
+	spec, err := get(serviceName)
 	if err != nil {
 		h.logger.Warn("spec not found", "service", serviceName)
 		http.Error(w, "spec not found", http.StatusNotFound)
@@ -57,3 +68,80 @@ func (h *SpecsHandler) Get(w http.ResponseWriter, r *http.Request) {
 		h.logger.Error("failed to write response", "error", err)
 	}
 }
+
+// diagnosticsResponse is the body of GET /api/specs/{service}/diagnostics
+type diagnosticsResponse struct {
+	Service     string               `json:"service"`
+	Valid       bool                 `json:"valid"`
+	Diagnostics []storage.Diagnostic `json:"diagnostics"`
+}
+
+// Diagnostics handles GET /api/specs/{service}/diagnostics - returns validation findings
+func (h *SpecsHandler) Diagnostics(w http.ResponseWriter, r *http.Request) {
+	serviceName := r.PathValue("service")
+	if serviceName == "" {
+		http.Error(w, "service name required", http.StatusBadRequest)
+		return
+	}
+
+	diags, err := h.store.Diagnostics(serviceName)
+	if err != nil {
+		h.logger.Warn("spec not found", "service", serviceName)
+		http.Error(w, "spec not found", http.StatusNotFound)
+		return
+	}
+
+	resp := diagnosticsResponse{
+		Service:     serviceName,
+		Valid:       true,
+		Diagnostics: diags,
+	}
+	for _, d := range diags {
+		if d.Severity == storage.SeverityError {
+			resp.Valid = false
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+// environmentsResponse is the body of GET /api/specs/{service}/environments
+type environmentsResponse struct {
+	Service            string                      `json:"service"`
+	DefaultEnvironment string                      `json:"defaultEnvironment,omitempty"`
+	Environments       []storage.PublicEnvironment `json:"environments"`
+}
+
+// Environments handles GET /api/specs/{service}/environments - lists environments without secrets
+func (h *SpecsHandler) Environments(w http.ResponseWriter, r *http.Request) {
+	serviceName := r.PathValue("service")
+	if serviceName == "" {
+		http.Error(w, "service name required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.store.Get(serviceName); err != nil {
+		h.logger.Warn("spec not found", "service", serviceName)
+		http.Error(w, "spec not found", http.StatusNotFound)
+		return
+	}
+
+	resp := environmentsResponse{
+		Service:      serviceName,
+		Environments: []storage.PublicEnvironment{},
+	}
+
+	// Services without x-proxy-config have no environments to offer
+	if config, err := h.store.GetConfig(serviceName); err == nil {
+		resp.DefaultEnvironment = config.DefaultEnvironment
+		resp.Environments = config.PublicEnvironments()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
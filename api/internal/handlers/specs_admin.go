@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// maxSpecUploadBytes caps the size of an uploaded spec document
+const maxSpecUploadBytes = 10 << 20
+
+// SpecsAdminHandler handles spec management endpoints
+// Every request must carry the admin token as a bearer token
+type SpecsAdminHandler struct {
+	logger *slog.Logger
+	store  storage.SpecWriter
+	token  string
+}
+
+// NewSpecsAdminHandler creates a new spec management handler
+func NewSpecsAdminHandler(logger *slog.Logger, store storage.SpecWriter, token string) *SpecsAdminHandler {
+	return &SpecsAdminHandler{
+		logger: logger,
+		store:  store,
+		token:  token,
+	}
+}
+
+// Create handles POST /api/specs?service={service} - publishes a new spec
+func (h *SpecsAdminHandler) Create(w http.ResponseWriter, r *http.Request) {
+	h.put(w, r, r.URL.Query().Get("service"), false)
+}
+
+// Replace handles PUT /api/specs/{service} - creates or replaces a spec
+func (h *SpecsAdminHandler) Replace(w http.ResponseWriter, r *http.Request) {
+	h.put(w, r, r.PathValue("service"), true)
+}
+
+// Delete handles DELETE /api/specs/{service} - removes a spec
+func (h *SpecsAdminHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	serviceName := r.PathValue("service")
+	if serviceName == "" {
+		http.Error(w, "service name required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.Delete(serviceName); err != nil {
+		if errors.Is(err, storage.ErrServiceNotFound) {
+			http.Error(w, "spec not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to delete spec", "service", serviceName, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Info("spec deleted", "service", serviceName)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// put reads the request body and stores it as the service's spec
+func (h *SpecsAdminHandler) put(w http.ResponseWriter, r *http.Request, serviceName string, overwrite bool) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	if serviceName == "" {
+		http.Error(w, "service name required", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSpecUploadBytes))
+	if err != nil {
+		http.Error(w, "spec too large or unreadable", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	created, err := h.store.Put(serviceName, data, overwrite)
+	if err != nil {
+		var validationErr *storage.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			h.logger.Warn("rejected spec upload", "service", serviceName, "error", err)
+			h.writeJSON(w, http.StatusBadRequest, diagnosticsResponse{
+				Service:     serviceName,
+				Diagnostics: validationErr.Diagnostics,
+			})
+		case errors.Is(err, storage.ErrInvalidSpec):
+			h.logger.Warn("rejected spec upload", "service", serviceName, "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, storage.ErrServiceExists):
+			http.Error(w, "spec already exists", http.StatusConflict)
+		default:
+			h.logger.Error("failed to store spec", "service", serviceName, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		w.Header().Set("Location", "/api/specs/"+serviceName)
+	}
+
+	h.logger.Info("spec published", "service", serviceName, "created", created)
+
+	h.writeJSON(w, status, map[string]string{"service": serviceName})
+}
+
+// writeJSON writes v as a JSON response with the given status
+func (h *SpecsAdminHandler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
+
+// authorized checks the bearer token, writing a 401 when it does not match
+func (h *SpecsAdminHandler) authorized(w http.ResponseWriter, r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if ok && h.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1 {
+		return true
+	}
+
+	w.Header().Set("WWW-Authenticate", `Bearer realm="specs"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+	return false
+}
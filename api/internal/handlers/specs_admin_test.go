@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"jonathanmcclement.com/playground/internal/handlers"
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// mockSpecWriter implements storage.SpecWriter for testing
+type mockSpecWriter struct {
+	specs map[string]string
+}
+
+func (m *mockSpecWriter) Put(serviceName string, data []byte, overwrite bool) (bool, error) {
+	if !strings.Contains(string(data), "openapi") {
+		return false, errors.Join(storage.ErrInvalidSpec, errors.New("missing openapi version field"))
+	}
+	_, exists := m.specs[serviceName]
+	if exists && !overwrite {
+		return false, storage.ErrServiceExists
+	}
+	m.specs[serviceName] = string(data)
+	return !exists, nil
+}
+
+func (m *mockSpecWriter) Delete(serviceName string) error {
+	if _, exists := m.specs[serviceName]; !exists {
+		return storage.ErrServiceNotFound
+	}
+	delete(m.specs, serviceName)
+	return nil
+}
+
+func TestSpecsAdminHandler(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	writer := &mockSpecWriter{specs: map[string]string{"existing": `{"openapi":"3.0.0"}`}}
+	handler := handlers.NewSpecsAdminHandler(logger, writer, "admin-token")
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		service    string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{"create", http.MethodPost, "/api/specs?service=pets", "", "admin-token", `{"openapi":"3.0.0"}`, http.StatusCreated},
+		{"create conflict", http.MethodPost, "/api/specs?service=existing", "", "admin-token", `{"openapi":"3.0.0"}`, http.StatusConflict},
+		{"create missing name", http.MethodPost, "/api/specs", "", "admin-token", `{"openapi":"3.0.0"}`, http.StatusBadRequest},
+		{"create invalid", http.MethodPost, "/api/specs?service=bad", "", "admin-token", `{}`, http.StatusBadRequest},
+		{"create unauthorized", http.MethodPost, "/api/specs?service=pets2", "", "wrong", `{"openapi":"3.0.0"}`, http.StatusUnauthorized},
+		{"replace existing", http.MethodPut, "/api/specs/existing", "existing", "admin-token", `{"openapi":"3.1.0"}`, http.StatusOK},
+		{"replace creates", http.MethodPut, "/api/specs/new", "new", "admin-token", `{"openapi":"3.1.0"}`, http.StatusCreated},
+		{"replace unauthorized", http.MethodPut, "/api/specs/existing", "existing", "", `{"openapi":"3.1.0"}`, http.StatusUnauthorized},
+		{"delete", http.MethodDelete, "/api/specs/existing", "existing", "admin-token", "", http.StatusNoContent},
+		{"delete not found", http.MethodDelete, "/api/specs/missing", "missing", "admin-token", "", http.StatusNotFound},
+		{"delete unauthorized", http.MethodDelete, "/api/specs/new", "new", "", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.service != "" {
+				req.SetPathValue("service", tt.service)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+
+			switch tt.method {
+			case http.MethodPost:
+				handler.Create(rec, req)
+			case http.MethodPut:
+				handler.Replace(rec, req)
+			case http.MethodDelete:
+				handler.Delete(rec, req)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	if _, exists := writer.specs["pets"]; !exists {
+		t.Error("expected 'pets' to be stored")
+	}
+	if _, exists := writer.specs["pets2"]; exists {
+		t.Error("expected unauthorized upload to be rejected")
+	}
+}
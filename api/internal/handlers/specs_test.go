@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"jonathanmcclement.com/playground/internal/handlers"
@@ -14,8 +15,10 @@ import (
 
 // mockSpecStore implements storage.SpecStore for testing
 type mockSpecStore struct {
-	specs   map[string]json.RawMessage
-	configs map[string]*storage.ServiceConfig
+	specs       map[string]json.RawMessage
+	originals   map[string]json.RawMessage
+	configs     map[string]*storage.ServiceConfig
+	diagnostics map[string][]storage.Diagnostic
 }
 
 func (m *mockSpecStore) List() ([]string, error) {
@@ -34,6 +37,20 @@ func (m *mockSpecStore) Get(serviceName string) (json.RawMessage, error) {
 	return spec, nil
 }
 
+func (m *mockSpecStore) GetOriginal(serviceName string) (json.RawMessage, error) {
+	if original, exists := m.originals[serviceName]; exists {
+		return original, nil
+	}
+	return m.Get(serviceName)
+}
+
+func (m *mockSpecStore) Diagnostics(serviceName string) ([]storage.Diagnostic, error) {
+	if _, exists := m.specs[serviceName]; !exists {
+		return nil, storage.ErrServiceNotFound
+	}
+	return append([]storage.Diagnostic{}, m.diagnostics[serviceName]...), nil
+}
+
 func (m *mockSpecStore) GetConfig(serviceName string) (*storage.ServiceConfig, error) {
 	config, exists := m.configs[serviceName]
 	if !exists {
@@ -143,6 +160,47 @@ func TestSpecsHandler_Get(t *testing.T) {
 	}
 }
 
+func TestSpecsHandler_Get_Format(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	store := &mockSpecStore{
+		specs: map[string]json.RawMessage{
+			"legacy": json.RawMessage(`{"openapi":"3.0.3"}`),
+		},
+		originals: map[string]json.RawMessage{
+			"legacy": json.RawMessage(`{"swagger":"2.0"}`),
+		},
+	}
+
+	handler := handlers.NewSpecsHandler(logger, store)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{query: "", wantStatus: http.StatusOK, wantBody: `{"openapi":"3.0.3"}`},
+		{query: "?format=original", wantStatus: http.StatusOK, wantBody: `{"swagger":"2.0"}`},
+		{query: "?format=xml", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/specs/legacy"+tt.query, nil)
+			req.SetPathValue("service", "legacy")
+			rec := httptest.NewRecorder()
+
+			handler.Get(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %s, got %s", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
 func TestSpecsHandler_Get_NotFound(t *testing.T) {
 	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
 	store := &mockSpecStore{
@@ -180,3 +238,140 @@ func TestSpecsHandler_Get_NoServiceName(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
 	}
 }
+
+func TestSpecsHandler_Diagnostics(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	store := &mockSpecStore{
+		specs: map[string]json.RawMessage{
+			"broken": json.RawMessage(`{"openapi":"3.0.0"}`),
+			"clean":  json.RawMessage(`{"openapi":"3.0.0"}`),
+		},
+		diagnostics: map[string][]storage.Diagnostic{
+			"broken": {
+				{Severity: storage.SeverityError, Pointer: "/paths", Message: "missing required object paths"},
+				{Severity: storage.SeverityWarning, Pointer: "/paths/~1pets~1{id}/get/parameters", Message: "path parameter \"id\" is not declared"},
+			},
+		},
+	}
+
+	handler := handlers.NewSpecsHandler(logger, store)
+
+	tests := []struct {
+		service    string
+		wantStatus int
+		wantValid  bool
+		wantCount  int
+	}{
+		{"broken", http.StatusOK, false, 2},
+		{"clean", http.StatusOK, true, 0},
+		{"missing", http.StatusNotFound, false, 0},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/specs/"+tt.service+"/diagnostics", nil)
+		req.SetPathValue("service", tt.service)
+		rec := httptest.NewRecorder()
+
+		handler.Diagnostics(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.service, tt.wantStatus, rec.Code)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+
+		var resp struct {
+			Valid       bool                 `json:"valid"`
+			Diagnostics []storage.Diagnostic `json:"diagnostics"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.service, err)
+		}
+
+		if resp.Valid != tt.wantValid {
+			t.Errorf("%s: expected valid=%v, got %v", tt.service, tt.wantValid, resp.Valid)
+		}
+		if resp.Diagnostics == nil || len(resp.Diagnostics) != tt.wantCount {
+			t.Errorf("%s: expected %d diagnostics, got %v", tt.service, tt.wantCount, resp.Diagnostics)
+		}
+	}
+}
+
+func TestSpecsHandler_Environments(t *testing.T) {
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	store := &mockSpecStore{
+		specs: map[string]json.RawMessage{
+			"multi":  json.RawMessage(`{"openapi":"3.0.0"}`),
+			"plain":  json.RawMessage(`{"openapi":"3.0.0"}`),
+			"single": json.RawMessage(`{"openapi":"3.0.0"}`),
+		},
+		configs: map[string]*storage.ServiceConfig{
+			"multi": {
+				DefaultEnvironment: "dev",
+				Environments: map[string]*storage.EnvironmentConfig{
+					"prod": {BaseURL: "https://api.example.com?key=abc", AuthHeaders: map[string]string{"X-Api-Key": "secret"}},
+					"dev":  {BaseURL: "http://localhost:8081"},
+				},
+			},
+			"single": {BaseURL: "https://single.example.com"},
+		},
+	}
+
+	handler := handlers.NewSpecsHandler(logger, store)
+
+	tests := []struct {
+		service     string
+		wantStatus  int
+		wantDefault string
+		wantNames   []string
+	}{
+		{service: "multi", wantStatus: http.StatusOK, wantDefault: "dev", wantNames: []string{"dev", "prod"}},
+		{service: "single", wantStatus: http.StatusOK, wantNames: []string{}},
+		{service: "plain", wantStatus: http.StatusOK, wantNames: []string{}},
+		{service: "missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/specs/"+tt.service+"/environments", nil)
+			req.SetPathValue("service", tt.service)
+			rec := httptest.NewRecorder()
+
+			handler.Environments(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			body := rec.Body.String()
+			if strings.Contains(body, "secret") || strings.Contains(body, "key=abc") {
+				t.Errorf("response leaks secrets: %s", body)
+			}
+
+			var resp struct {
+				DefaultEnvironment string                      `json:"defaultEnvironment"`
+				Environments       []storage.PublicEnvironment `json:"environments"`
+			}
+			if err := json.Unmarshal([]byte(body), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if resp.DefaultEnvironment != tt.wantDefault {
+				t.Errorf("expected default %q, got %q", tt.wantDefault, resp.DefaultEnvironment)
+			}
+			if resp.Environments == nil || len(resp.Environments) != len(tt.wantNames) {
+				t.Fatalf("expected environments %v, got %+v", tt.wantNames, resp.Environments)
+			}
+			for i, name := range tt.wantNames {
+				if resp.Environments[i].Name != name {
+					t.Errorf("environment %d: expected %s, got %s", i, name, resp.Environments[i].Name)
+				}
+			}
+		})
+	}
+}
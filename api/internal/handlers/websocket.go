@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"jonathanmcclement.com/playground/internal/proxy"
+)
+
+// WebSocket handles GET /ws/{service}/{path...}
+// The client's WebSocket handshake is sent to the service's base URL with its auth injected.
+// Once the backend switches protocols the connection is hijacked and frames are relayed both
+// ways until either side closes or the proxy's idle or total duration limit is reached.
+// A backend refusing the upgrade is answered with its own response, like Pass.
+func (h *ProxyHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		writeProblem(w, h.logger, newProblem(http.StatusBadRequest, CodeInvalidRequest, "Invalid proxy request", "expected a WebSocket upgrade request"))
+		return
+	}
+
+	service := r.PathValue("service")
+	req := proxy.Request{
+		Service: service,
+		Method:  r.Method,
+		Path:    upstreamPath(r, "/ws/"+service),
+		Raw:     &proxy.RawRequest{Header: forwardedHeader(r)},
+	}
+
+	h.logger.Info("opening websocket", "service", req.Service, "path", req.Path)
+
+	resp, err := h.proxyClient.DialWebSocket(r.Context(), &req)
+	if err != nil {
+		h.writeProxyError(w, &req, err)
+		return
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		h.copyStream(w, &req, resp)
+		return
+	}
+	upstream := resp.Body.(io.ReadWriteCloser)
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		upstream.Close()
+		h.logger.Error("websocket hijack failed", "error", err, "service", req.Service)
+		writeProblem(w, h.logger, newProblem(http.StatusInternalServerError, CodeProxyError, "Proxy request failed", "connection cannot be upgraded"))
+		return
+	}
+
+	if err := writeSwitchingProtocols(rw.Writer, resp.Header); err != nil {
+		conn.Close()
+		upstream.Close()
+		h.logger.Warn("websocket client went away", "error", err, "service", req.Service)
+		return
+	}
+
+	// Bytes the server read ahead of the handshake belong to the client's first frames
+	client := &hijackedConn{Reader: rw.Reader, Conn: conn}
+	err = h.proxyClient.RelayWebSocket(r.Context(), client, upstream)
+	switch {
+	case errors.Is(err, proxy.ErrWebSocketIdle), errors.Is(err, proxy.ErrWebSocketMaxDuration):
+		h.logger.Info("websocket closed by proxy", "reason", err, "service", req.Service)
+	case err != nil:
+		h.logger.Warn("websocket relay ended", "error", err, "service", req.Service)
+	default:
+		h.logger.Info("websocket closed", "service", req.Service)
+	}
+}
+
+// writeSwitchingProtocols completes the client's handshake with the backend's answer
+func writeSwitchingProtocols(w *bufio.Writer, upstream http.Header) error {
+	header := make(http.Header)
+	for name, values := range upstream {
+		// CORS is decided by this server, not the backend
+		if !strings.HasPrefix(strings.ToLower(name), "access-control-") {
+			header[name] = values
+		}
+	}
+	header.Set("Connection", "Upgrade")
+	header.Set("Upgrade", "websocket")
+
+	w.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	header.Write(w)
+	w.WriteString("\r\n")
+	return w.Flush()
+}
+
+// hijackedConn reads through the server's buffer before the raw connection
+type hijackedConn struct {
+	io.Reader
+	net.Conn
+}
+
+func (c *hijackedConn) Read(p []byte) (int, error) {
+	return c.Reader.Read(p)
+}
+
+// headerHasToken reports whether a comma-separated header holds token, ignoring case
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
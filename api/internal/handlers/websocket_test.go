@@ -0,0 +1,223 @@
+package handlers_test
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/handlers"
+	"jonathanmcclement.com/playground/internal/proxy"
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// webSocketGUID is the RFC 6455 constant mixed into Sec-WebSocket-Accept
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// writeFrame writes a final frame with a short payload; clients must mask, servers must not
+func writeFrame(w io.Writer, opcode byte, payload []byte, masked bool) error {
+	header := []byte{0x80 | opcode, byte(len(payload))}
+	if !masked {
+		_, err := w.Write(append(header, payload...))
+		return err
+	}
+	mask := []byte{1, 2, 3, 4}
+	header[1] |= 0x80
+	data := append(header, mask...)
+	for i, b := range payload {
+		data = append(data, b^mask[i%4])
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readFrame reads a frame with a short payload, unmasking it if needed
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := int(header[1] & 0x7f)
+	if length >= 126 {
+		return 0, nil, fmt.Errorf("payload too long for this test: %d", length)
+	}
+	var mask [4]byte
+	masked := header[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return header[0] & 0x0f, payload, nil
+}
+
+// newEchoServer is a minimal WebSocket server that echoes each frame and records the handshake
+func newEchoServer(t *testing.T, handshakes chan<- http.Header) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handshakes <- r.Header.Clone()
+
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+		rw.Flush()
+
+		for {
+			opcode, payload, err := readFrame(rw.Reader)
+			if err != nil {
+				return
+			}
+			if err := writeFrame(conn, opcode, payload, false); err != nil || opcode == 0x8 {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dialWebSocket performs a client handshake against the proxy and returns the open connection
+func dialWebSocket(t *testing.T, proxyURL, path string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(proxyURL, "http://"))
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: playground\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n", path, key)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("reading handshake failed: %v", err)
+	}
+	if resp.StatusCode == http.StatusSwitchingProtocols && resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		t.Errorf("expected the backend's accept key, got %v", resp.Header)
+	}
+	return conn, reader, resp
+}
+
+func newWebSocketProxy(t *testing.T, baseURL string, opts ...proxy.Option) *httptest.Server {
+	t.Helper()
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"chat": {
+				BaseURL:     baseURL,
+				AuthHeaders: map[string]string{"Authorization": "Bearer backend-token"},
+			},
+		},
+	}
+
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	handler := handlers.NewProxyHandler(logger, proxy.NewClient(store, opts...))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ws/{service}/{path...}", handler.WebSocket)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProxyHandler_WebSocket_Echo(t *testing.T) {
+	handshakes := make(chan http.Header, 1)
+	backend := newEchoServer(t, handshakes)
+	server := newWebSocketProxy(t, "ws"+strings.TrimPrefix(backend.URL, "http"))
+
+	conn, reader, resp := dialWebSocket(t, server.URL, "/ws/chat/rooms/1?user=ada")
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+
+	upstream := <-handshakes
+	if got := upstream.Get("Authorization"); got != "Bearer backend-token" {
+		t.Errorf("expected auth to be injected upstream, got %q", got)
+	}
+
+	for _, message := range []string{"hello", "world"} {
+		if err := writeFrame(conn, 0x1, []byte(message), true); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		opcode, payload, err := readFrame(reader)
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if opcode != 0x1 || string(payload) != message {
+			t.Errorf("expected text frame %q, got opcode %d %q", message, opcode, payload)
+		}
+	}
+
+	// The close handshake passes through too
+	if err := writeFrame(conn, 0x8, []byte{0x03, 0xe8}, true); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if opcode, _, err := readFrame(reader); err != nil || opcode != 0x8 {
+		t.Errorf("expected a close frame back, got opcode %d (%v)", opcode, err)
+	}
+}
+
+func TestProxyHandler_WebSocket_IdleTimeout(t *testing.T) {
+	backend := newEchoServer(t, make(chan http.Header, 1))
+	server := newWebSocketProxy(t, backend.URL, proxy.WithWebSocketLimits(100*time.Millisecond, time.Minute))
+
+	conn, reader, resp := dialWebSocket(t, server.URL, "/ws/chat/rooms/1")
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := reader.ReadByte(); err != io.EOF {
+		t.Errorf("expected the proxy to close the idle connection, got %v", err)
+	}
+}
+
+func TestProxyHandler_WebSocket_NotAnUpgrade(t *testing.T) {
+	server := newWebSocketProxy(t, "ws://chat.invalid")
+
+	resp, err := http.Get(server.URL + "/ws/chat/rooms/1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest || resp.Header.Get("Content-Type") != "application/problem+json" {
+		t.Errorf("expected a 400 problem, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestProxyHandler_WebSocket_UnknownService(t *testing.T) {
+	server := newWebSocketProxy(t, "ws://chat.invalid")
+
+	_, _, resp := dialWebSocket(t, server.URL, "/ws/missing/rooms/1")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,238 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Body modes accepted in Request.BodyMode
+const (
+	BodyModeJSON      = "json"      // Body is raw JSON forwarded as-is
+	BodyModeForm      = "form"      // Parts (or a flat Body object) sent as application/x-www-form-urlencoded
+	BodyModeMultipart = "multipart" // Parts sent as multipart/form-data, file parts carry base64 Data
+	BodyModeText      = "text"      // Body is a JSON string sent as plain text
+	BodyModeBinary    = "binary"    // Body is a JSON string of base64 bytes sent as application/octet-stream
+)
+
+// BodyPart is a single form field or file in a form or multipart body
+type BodyPart struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`       // Text value for plain fields
+	Filename    string `json:"filename,omitempty"`    // Set for file parts
+	ContentType string `json:"contentType,omitempty"` // Content type of a file part
+	Data        string `json:"data,omitempty"`        // Base64 file content
+}
+
+// encodedBody is a request body ready to send upstream
+type encodedBody struct {
+	reader      io.Reader
+	contentType string // Content-Type required by the encoding, empty to keep the caller's
+	fixed       bool   // Content-Type must override the caller's (multipart boundary)
+}
+
+// bodyModeForMediaType maps a media type to the body mode that produces it
+func bodyModeForMediaType(mediaType string) string {
+	mediaType, _, _ = mime.ParseMediaType(mediaType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return BodyModeForm
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return BodyModeMultipart
+	case isJSONMediaType(mediaType):
+		return BodyModeJSON
+	case isTextMediaType(mediaType):
+		return BodyModeText
+	case mediaType == "":
+		return ""
+	default:
+		return BodyModeBinary
+	}
+}
+
+// defaultContentType is sent when neither the caller nor the spec chose a media type
+func defaultContentType(mode string) string {
+	switch mode {
+	case BodyModeForm:
+		return "application/x-www-form-urlencoded"
+	case BodyModeText:
+		return "text/plain; charset=utf-8"
+	case BodyModeBinary:
+		return "application/octet-stream"
+	default:
+		return "application/json"
+	}
+}
+
+// encodeRequestBody builds the upstream body for the given mode
+func encodeRequestBody(req *Request, mode string) (*encodedBody, error) {
+	switch mode {
+	case BodyModeJSON, "":
+		if len(req.Body) == 0 {
+			return nil, nil
+		}
+		return &encodedBody{reader: bytes.NewReader(req.Body)}, nil
+
+	case BodyModeText:
+		if len(req.Body) == 0 {
+			return nil, nil
+		}
+		var text string
+		if err := json.Unmarshal(req.Body, &text); err != nil {
+			// Not a JSON string, send the raw bytes as text
+			text = string(req.Body)
+		}
+		return &encodedBody{reader: strings.NewReader(text)}, nil
+
+	case BodyModeBinary:
+		if len(req.Body) == 0 {
+			return nil, nil
+		}
+		var encoded string
+		if err := json.Unmarshal(req.Body, &encoded); err != nil {
+			return nil, fmt.Errorf("binary body must be a base64 string: %w", err)
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("binary body is not valid base64: %w", err)
+		}
+		return &encodedBody{reader: bytes.NewReader(data)}, nil
+
+	case BodyModeForm:
+		values, err := formValues(req)
+		if err != nil {
+			return nil, err
+		}
+		return &encodedBody{reader: strings.NewReader(values.Encode())}, nil
+
+	case BodyModeMultipart:
+		return encodeMultipart(req)
+
+	default:
+		return nil, fmt.Errorf("unsupported body mode: %s", mode)
+	}
+}
+
+// formValues collects form fields from Parts, or from a flat JSON object Body
+func formValues(req *Request) (url.Values, error) {
+	values := url.Values{}
+	for _, part := range req.Parts {
+		if part.Filename != "" {
+			return nil, fmt.Errorf("file part %s requires multipart body mode", part.Name)
+		}
+		values.Add(part.Name, part.Value)
+	}
+
+	if len(req.Parts) > 0 || len(req.Body) == 0 {
+		return values, nil
+	}
+
+	fields, err := flattenFields(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		values.Add(f.Name, f.Value)
+	}
+	return values, nil
+}
+
+// flattenFields turns a JSON object of scalars (or arrays of scalars) into ordered form fields
+func flattenFields(body json.RawMessage) ([]BodyPart, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("form body must be a JSON object: %w", err)
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []BodyPart
+	for _, name := range names {
+		items, ok := obj[name].([]interface{})
+		if !ok {
+			items = []interface{}{obj[name]}
+		}
+		for _, item := range items {
+			switch v := item.(type) {
+			case map[string]interface{}, []interface{}:
+				return nil, fmt.Errorf("form field %s must be a scalar value", name)
+			case nil:
+				fields = append(fields, BodyPart{Name: name})
+			default:
+				fields = append(fields, BodyPart{Name: name, Value: fmt.Sprint(v)})
+			}
+		}
+	}
+	return fields, nil
+}
+
+// encodeMultipart writes Parts (or a flat JSON object Body) as multipart/form-data
+func encodeMultipart(req *Request) (*encodedBody, error) {
+	parts := req.Parts
+	if len(parts) == 0 && len(req.Body) > 0 {
+		fields, err := flattenFields(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		parts = fields
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, part := range parts {
+		if part.Filename == "" {
+			if err := writer.WriteField(part.Name, part.Value); err != nil {
+				return nil, fmt.Errorf("failed to write field %s: %w", part.Name, err)
+			}
+			continue
+		}
+
+		data, err := base64.StdEncoding.DecodeString(part.Data)
+		if err != nil {
+			return nil, fmt.Errorf("file part %s is not valid base64: %w", part.Name, err)
+		}
+
+		contentType := part.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+			"name":     part.Name,
+			"filename": part.Filename,
+		}))
+		header.Set("Content-Type", contentType)
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create file part %s: %w", part.Name, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to write file part %s: %w", part.Name, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish multipart body: %w", err)
+	}
+
+	return &encodedBody{
+		reader:      &buf,
+		contentType: writer.FormDataContentType(),
+		fixed:       true,
+	}, nil
+}
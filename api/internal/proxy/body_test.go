@@ -0,0 +1,234 @@
+package proxy
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// captured holds what the backend received for a single request
+type captured struct {
+	contentType string
+	body        []byte
+	form        map[string][]string
+	files       map[string]string
+}
+
+// newCaptureBackend records the request body and any parsed form fields
+func newCaptureBackend(t *testing.T, got *captured) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.contentType = r.Header.Get("Content-Type")
+
+		switch {
+		case got.contentType == "application/x-www-form-urlencoded":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("failed to parse form: %v", err)
+			}
+			got.form = r.PostForm
+		case strings.HasPrefix(got.contentType, "multipart/form-data"):
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("failed to parse multipart form: %v", err)
+			}
+			got.form = r.MultipartForm.Value
+			got.files = make(map[string]string)
+			for name, headers := range r.MultipartForm.File {
+				f, _ := headers[0].Open()
+				data, _ := io.ReadAll(f)
+				got.files[name] = headers[0].Filename + ":" + string(data)
+			}
+		default:
+			got.body, _ = io.ReadAll(r.Body)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestClient_Forward_BodyModes(t *testing.T) {
+	tests := []struct {
+		name            string
+		req             Request
+		wantContentType string
+		check           func(t *testing.T, got *captured)
+	}{
+		{
+			name: "form from parts",
+			req: Request{
+				BodyMode: BodyModeForm,
+				Parts:    []BodyPart{{Name: "grant_type", Value: "password"}, {Name: "scope", Value: "a b"}},
+			},
+			wantContentType: "application/x-www-form-urlencoded",
+			check: func(t *testing.T, got *captured) {
+				if got.form["grant_type"][0] != "password" || got.form["scope"][0] != "a b" {
+					t.Errorf("unexpected form values: %v", got.form)
+				}
+			},
+		},
+		{
+			name: "form from object body",
+			req: Request{
+				BodyMode: BodyModeForm,
+				Body:     json.RawMessage(`{"name":"rex","age":3,"tags":["a","b"]}`),
+			},
+			wantContentType: "application/x-www-form-urlencoded",
+			check: func(t *testing.T, got *captured) {
+				if got.form["name"][0] != "rex" || got.form["age"][0] != "3" || len(got.form["tags"]) != 2 {
+					t.Errorf("unexpected form values: %v", got.form)
+				}
+			},
+		},
+		{
+			name: "multipart with file",
+			req: Request{
+				BodyMode: BodyModeMultipart,
+				Parts: []BodyPart{
+					{Name: "title", Value: "avatar"},
+					{Name: "file", Filename: "a.txt", ContentType: "text/plain", Data: base64.StdEncoding.EncodeToString([]byte("hello"))},
+				},
+			},
+			wantContentType: "multipart/form-data",
+			check: func(t *testing.T, got *captured) {
+				if got.form["title"][0] != "avatar" {
+					t.Errorf("expected title field 'avatar', got %v", got.form["title"])
+				}
+				if got.files["file"] != "a.txt:hello" {
+					t.Errorf("expected file 'a.txt:hello', got %q", got.files["file"])
+				}
+			},
+		},
+		{
+			name:            "text",
+			req:             Request{BodyMode: BodyModeText, Body: json.RawMessage(`"hello world"`)},
+			wantContentType: "text/plain; charset=utf-8",
+			check: func(t *testing.T, got *captured) {
+				if string(got.body) != "hello world" {
+					t.Errorf("expected body 'hello world', got %q", got.body)
+				}
+			},
+		},
+		{
+			name:            "binary",
+			req:             Request{BodyMode: BodyModeBinary, Body: json.RawMessage(`"` + base64.StdEncoding.EncodeToString([]byte{0, 1, 2}) + `"`)},
+			wantContentType: "application/octet-stream",
+			check: func(t *testing.T, got *captured) {
+				if string(got.body) != "\x00\x01\x02" {
+					t.Errorf("expected raw bytes, got %v", got.body)
+				}
+			},
+		},
+		{
+			name: "content type header selects mode",
+			req: Request{
+				Headers: map[string]string{"content-type": "text/csv"},
+				Body:    json.RawMessage(`"a,b\n1,2"`),
+			},
+			wantContentType: "text/csv",
+			check: func(t *testing.T, got *captured) {
+				if string(got.body) != "a,b\n1,2" {
+					t.Errorf("expected CSV body, got %q", got.body)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got captured
+			backend := newCaptureBackend(t, &got)
+			defer backend.Close()
+
+			store := &mockSpecStore{
+				configs: map[string]*storage.ServiceConfig{
+					"test-service": {BaseURL: backend.URL},
+				},
+			}
+
+			req := tt.req
+			req.Service = "test-service"
+			req.Method = http.MethodPost
+			req.Path = "/upload"
+
+			if _, err := NewClient(store).Forward(context.Background(), &req); err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+
+			if !strings.HasPrefix(got.contentType, tt.wantContentType) {
+				t.Errorf("expected Content-Type %q, got %q", tt.wantContentType, got.contentType)
+			}
+			tt.check(t, &got)
+		})
+	}
+}
+
+func TestClient_Forward_BodyModeFromSpec(t *testing.T) {
+	var got captured
+	backend := newCaptureBackend(t, &got)
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		specs: map[string]json.RawMessage{
+			"test-service": json.RawMessage(`{
+				"openapi": "3.0.0",
+				"paths": {
+					"/token": {"post": {"requestBody": {"$ref": "#/components/requestBodies/Token"}}}
+				},
+				"components": {
+					"requestBodies": {
+						"Token": {"content": {"application/x-www-form-urlencoded": {}}}
+					}
+				}
+			}`),
+		},
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL},
+		},
+	}
+
+	req := &Request{
+		Service: "test-service",
+		Method:  http.MethodPost,
+		Path:    "/token",
+		Body:    json.RawMessage(`{"grant_type":"client_credentials"}`),
+	}
+
+	if _, err := NewClient(store).Forward(context.Background(), req); err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+
+	if got.contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("expected Content-Type from spec, got %q", got.contentType)
+	}
+
+	if got.form["grant_type"][0] != "client_credentials" {
+		t.Errorf("expected grant_type 'client_credentials', got %v", got.form["grant_type"])
+	}
+}
+
+func TestClient_Forward_InvalidBinaryBody(t *testing.T) {
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: "http://example.com"},
+		},
+	}
+
+	req := &Request{
+		Service:  "test-service",
+		Method:   http.MethodPost,
+		Path:     "/upload",
+		BodyMode: BodyModeBinary,
+		Body:     json.RawMessage(`"not base64!"`),
+	}
+
+	if _, err := NewClient(store).Forward(context.Background(), req); err == nil {
+		t.Fatal("expected error for invalid base64 body, got nil")
+	}
+}
@@ -2,82 +2,310 @@ package proxy
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/netip"
+	"strings"
 	"time"
 
 	"jonathanmcclement.com/playground/internal/storage"
 )
 
 // Request represents an incoming proxy request
+// BodyMode selects how Body and Parts are encoded; when empty it is inferred from
+// the Content-Type header, then the operation's requestBody.content, then JSON
 type Request struct {
-	Service string            `json:"service"`
-	Method  string            `json:"method"`
-	Path    string            `json:"path"`
-	Headers map[string]string `json:"headers"`
-	Body    json.RawMessage   `json:"body"` // Raw JSON to forward as-is
+	Service  string            `json:"service"`
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Headers  map[string]string `json:"headers"`
+	Body     json.RawMessage   `json:"body"` // Raw JSON, or a string for text/binary modes
+	BodyMode string            `json:"bodyMode,omitempty"`
+	Parts    []BodyPart        `json:"parts,omitempty"` // Fields and files for form/multipart modes
+
+	// Environment selects one of the service's x-proxy-config environments; empty uses the default
+	Environment string `json:"environment,omitempty"`
+
+	// Server selects an entry of the spec's servers[] as the target instead of x-proxy-config.baseURL
+	// Without x-proxy-config the first server is used. ServerVariables fill in {variables} in its URL.
+	// The service's credentials only go along when the server is the configured host, see resolveTarget.
+	Server          *int              `json:"server,omitempty"`
+	ServerVariables map[string]string `json:"serverVariables,omitempty"`
+
+	// Validate checks the request against its spec operation before forwarding, even when the
+	// client does not validate every request; Force skips validation either way
+	Validate bool `json:"validate,omitempty"`
+	Force    bool `json:"force,omitempty"`
+
+	// Timeout overrides the service's response timeout as a Go duration, e.g. 10s
+	// It is capped at the client's maximum, see WithMaxTimeout.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Raw makes this a pass-through request whose own headers and body are sent as they are,
+	// in place of Headers, Body, BodyMode and Parts; it is never decoded from JSON
+	Raw *RawRequest `json:"-"`
+}
+
+// RawRequest holds the headers and body of a request forwarded verbatim
+// Hop-by-hop headers are dropped; everything else, Content-Type included, is sent unchanged.
+type RawRequest struct {
+	Header http.Header
+	Body   []byte
 }
 
 // Response represents a proxied response
+// BodyEncoding tells the caller how to read Body: raw JSON, a text string or base64 bytes.
+// Validation compares the response with the spec and is omitted when no operation matches.
+// Timings breaks down where the time went, from DNS lookup to reading the body.
+// Attempts lists every call made under the service's retry policy and is omitted without one.
 type Response struct {
-	StatusCode int                 `json:"statusCode"`
-	Headers    map[string][]string `json:"headers"`
-	Body       json.RawMessage     `json:"body"`
+	StatusCode   int                 `json:"statusCode"`
+	Headers      map[string][]string `json:"headers"`
+	Body         json.RawMessage     `json:"body"`
+	BodyEncoding string              `json:"bodyEncoding"`
+	Truncated    bool                `json:"truncated,omitempty"` // Body was cut off at the client's maximum size
+	Validation   *ResponseValidation `json:"validation,omitempty"`
+	Timings      *Timings            `json:"timings"` // Of the last attempt
+	Attempts     []Attempt           `json:"attempts,omitempty"`
 }
 
 // Client handles proxying requests to backend services
 type Client struct {
-	httpClient *http.Client
-	store      storage.SpecStore
+	httpClient         *http.Client
+	store              storage.SpecStore
+	validateRequests   bool          // Validate every request against its spec operation
+	maxTimeout         time.Duration // Cap on service and per-request timeouts
+	maxBodySize        int64         // Most bytes of an upstream body Forward buffers
+	maxRequestBodySize int64         // Most bytes of a pass-through request body
+	wsIdleTimeout      time.Duration // Relayed WebSockets close after this long without traffic
+	wsMaxDuration      time.Duration // Relayed WebSockets close this long after opening
+	transports         transportCache
+	specs              specCache
+	tokens             tokenCache
+	signers            map[string]SignerFactory // Signer types by x-proxy-config signing.type
+	deniedNetworks     []netip.Prefix           // Addresses the proxy must never dial
+	logger             *slog.Logger
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithRequestValidation makes the client validate every request against its spec operation
+// before forwarding; requests can still bypass it with Force
+func WithRequestValidation(enabled bool) Option {
+	return func(c *Client) {
+		c.validateRequests = enabled
+	}
+}
+
+// WithLogger sets the logger for proxy warnings, e.g. disabled certificate verification
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
 }
 
 // NewClient creates a new proxy client
-func NewClient(store storage.SpecStore) *Client {
-	return &Client{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		store: store,
+func NewClient(store storage.SpecStore, opts ...Option) *Client {
+	c := &Client{
+		store:              store,
+		maxTimeout:         DefaultMaxTimeout,
+		maxBodySize:        DefaultMaxBodySize,
+		maxRequestBodySize: DefaultMaxRequestBodySize,
+		wsIdleTimeout:      DefaultWebSocketIdleTimeout,
+		wsMaxDuration:      DefaultWebSocketMaxDuration,
+		transports:         transportCache{entries: make(map[string]*serviceTransport)},
+		specs:              specCache{entries: make(map[string]*cachedSpec)},
+		tokens:             tokenCache{entries: make(map[string]*tokenEntry)},
+		signers:            make(map[string]SignerFactory, len(builtinSigners)),
+		logger:             slog.Default(),
+	}
+	for signerType, factory := range builtinSigners {
+		c.signers[signerType] = factory
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Timeouts are set per request from x-proxy-config, so the client has none of its own
+	c.httpClient = &http.Client{
+		Transport:     c.newTransport(nil),
+		CheckRedirect: checkRedirect,
 	}
+	return c
 }
 
 // Forward sends the request to the appropriate backend service
-// Adds auth headers from config, merges with request headers
-func (c *Client) Forward(req *Request) (*Response, error) {
+// Adds auth headers from config, merges with request headers. Canceling ctx aborts the
+// upstream call. Errors wrap one of the Err* failure classes, or are a *ValidationError
+// when request validation fails. Bodies over the client's maximum are cut short and
+// flagged as Truncated, see WithMaxBodySize.
+func (c *Client) Forward(ctx context.Context, req *Request) (*Response, error) {
+	call, err := c.prepare(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer call.cancel(nil)
+
+	ex, attempts, err := c.send(call, c.maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, respBody := ex.resp, ex.body
+
+	// Encode body so non-JSON payloads survive the JSON envelope
+	body, encoding, err := encodeBody(httpResp.Header.Get("Content-Type"), respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response body: %w", err)
+	}
+
+	// A cut-off body cannot be checked against its schema, but status and headers still can
+	validationBody := respBody
+	if ex.truncated {
+		validationBody = nil
+	}
+
+	// Build response
+	resp := &Response{
+		StatusCode:   httpResp.StatusCode,
+		Headers:      httpResp.Header,
+		Body:         body,
+		BodyEncoding: encoding,
+		Truncated:    ex.truncated,
+		Validation:   c.validateResponse(call.spec, req, httpResp.StatusCode, httpResp.Header, validationBody),
+		Timings:      ex.timings,
+		Attempts:     attempts,
+	}
+
+	return resp, nil
+}
+
+// upstreamCall is a request ready to send upstream, shared by Forward and Stream
+type upstreamCall struct {
+	ctx           context.Context
+	cancel        context.CancelCauseFunc // Releases ctx; must be called once the call is done
+	service       string
+	spec          *serviceSpec // Service spec loaded once for the whole call, nil if unavailable
+	httpReq       *http.Request
+	httpClient    *http.Client
+	oauth         *storage.OAuth2Config
+	authorization string // OAuth2 header value sent, to recognize a rejection of our own token
+	policy        *retryPolicy
+	headerTimer   *time.Timer // Streaming only: fires the response timeout until headers arrive
+}
+
+// prepare resolves the target, validates and encodes the request and sets its headers and signature
+// Buffered calls give the response timeout to the whole exchange; streaming calls only to
+// receiving the response headers, so long-lived bodies are bounded by the caller alone.
+func (c *Client) prepare(ctx context.Context, req *Request, streaming bool) (*upstreamCall, error) {
 	// Validate method
 	if !isValidHTTPMethod(req.Method) {
-		return nil, fmt.Errorf("invalid HTTP method: %s", req.Method)
+		return nil, invalidRequest("invalid HTTP method: %s", req.Method)
+	}
+
+	// Decode the spec once; servers, body modes and validation all read it and
+	// report a missing one themselves when they need it
+	spec, _ := c.loadSpec(req.Service)
+
+	// Pick the base URL and auth from x-proxy-config or the spec's servers
+	target, credentials, err := c.resolveTarget(spec, req)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get service config
-	config, err := c.store.GetConfig(req.Service)
+	connectTimeout, responseTimeout, err := c.timeouts(req)
 	if err != nil {
-		return nil, fmt.Errorf("service not found: %s", req.Service)
+		return nil, err
 	}
 
-	// Construct target URL
-	targetURL := config.BaseURL + req.Path
+	// Join the path onto the base URL without letting it change the host
+	targetURL, err := joinTarget(target.BaseURL, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	// ws(s) base URLs name WebSocket services; their handshake is plain HTTP(S)
+	targetURL = webSocketTarget(targetURL)
+
+	// Encode body according to its mode; raw bodies are sent as they came
+	var mode, mediaType string
+	var reqBody *encodedBody
+	if req.Raw != nil {
+		if len(req.Raw.Body) > 0 {
+			reqBody = &encodedBody{reader: bytes.NewReader(req.Raw.Body)}
+		}
+	} else {
+		mode, mediaType = c.resolveBodyMode(spec, req)
+
+		// Check the request against the spec instead of letting the backend reject it
+		if (c.validateRequests || req.Validate) && !req.Force {
+			if err := c.validateRequest(spec, req, target.AuthHeaders, mode, mediaType); err != nil {
+				return nil, err
+			}
+		}
+
+		if reqBody, err = encodeRequestBody(req, mode); err != nil {
+			return nil, invalidRequest("invalid request body: %w", err)
+		}
+	}
 
 	// Create HTTP request
 	var bodyReader io.Reader
-	if len(req.Body) > 0 {
-		bodyReader = bytes.NewReader(req.Body)
+	if reqBody != nil {
+		bodyReader = reqBody.reader
+	}
+
+	call := &upstreamCall{service: req.Service, spec: spec}
+	if streaming {
+		call.ctx, call.cancel = context.WithCancelCause(ctx)
+		call.headerTimer = time.AfterFunc(responseTimeout, func() { call.cancel(context.DeadlineExceeded) })
+	} else {
+		var cancel context.CancelFunc
+		call.ctx, cancel = context.WithTimeout(ctx, responseTimeout)
+		call.cancel = func(error) { cancel() }
+	}
+	ctx = context.WithValue(call.ctx, connectTimeoutKey{}, connectTimeout)
+	ctx = context.WithValue(ctx, redirectHostsKey{}, target.RedirectHosts)
+	call.ctx = ctx
+
+	// Any failure from here on must release the context
+	fail := func(err error) (*upstreamCall, error) {
+		call.stopHeaderTimer()
+		call.cancel(nil)
+		return nil, err
 	}
 
-	httpReq, err := http.NewRequest(req.Method, targetURL, bodyReader)
+	call.httpReq, err = http.NewRequestWithContext(ctx, req.Method, targetURL, bodyReader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fail(invalidRequest("failed to create request: %w", err))
+	}
+	httpReq := call.httpReq
+
+	if call.httpClient, err = c.httpClientFor(req.Service); err != nil {
+		return fail(err)
 	}
 
 	// Set auth headers from config first
-	if config.AuthHeaders != nil {
-		for key, value := range config.AuthHeaders {
+	if target.AuthHeaders != nil {
+		for key, value := range target.AuthHeaders {
 			httpReq.Header.Set(key, value)
 		}
 	}
 
+	// An OAuth2 token replaces any static Authorization; it only ever travels upstream
+	if credentials {
+		call.oauth = c.oauthConfig(req.Service)
+	}
+	if call.oauth != nil {
+		if call.authorization, err = c.authorization(ctx, call.httpClient, req.Service, call.oauth, ""); err != nil {
+			return fail(err)
+		}
+		httpReq.Header.Set("Authorization", call.authorization)
+	}
+
 	// Overlay request headers (allows override)
 	if req.Headers != nil {
 		for key, value := range req.Headers {
@@ -85,32 +313,120 @@ func (c *Client) Forward(req *Request) (*Response, error) {
 		}
 	}
 
+	// A pass-through request's own headers override just like Headers do
+	if req.Raw != nil {
+		header := req.Raw.Header.Clone()
+		removeHopHeaders(header)
+		for name, values := range header {
+			httpReq.Header[name] = values
+		}
+	}
+
 	// Set Content-Type if body is present and not already set
-	if len(req.Body) > 0 && httpReq.Header.Get("Content-Type") == "" {
-		httpReq.Header.Set("Content-Type", "application/json")
+	// Multipart always wins since the boundary must match the encoded body
+	if reqBody != nil && req.Raw == nil {
+		switch {
+		case reqBody.fixed:
+			httpReq.Header.Set("Content-Type", reqBody.contentType)
+		case httpReq.Header.Get("Content-Type") != "":
+		case mediaType != "":
+			httpReq.Header.Set("Content-Type", mediaType)
+		default:
+			httpReq.Header.Set("Content-Type", defaultContentType(mode))
+		}
 	}
 
-	// Execute request
-	httpResp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+	// Signatures cover the final headers and body, so sign last
+	if credentials {
+		if err := c.sign(req.Service, httpReq); err != nil {
+			return fail(err)
+		}
 	}
-	defer httpResp.Body.Close()
 
-	// Read response body
-	respBody, err := io.ReadAll(httpResp.Body)
+	call.policy = c.retryPolicy(req)
+	return call, nil
+}
+
+// send executes a prepared call, retrying per the service's policy; the response timeout spans every attempt
+// maxBody bounds how much of the body is read, see roundTrip; streamed bodies are left open.
+func (c *Client) send(call *upstreamCall, maxBody int64) (*exchange, []Attempt, error) {
+	ctx := call.ctx
+	ex, attempts, err := exchangeWithRetries(ctx, call.httpClient, call.httpReq, call.policy, maxBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, attempts, err
 	}
 
-	// Build response
-	resp := &Response{
-		StatusCode: httpResp.StatusCode,
-		Headers:    httpResp.Header,
-		Body:       json.RawMessage(respBody),
+	// A 401 for our own token usually means it was revoked early: get a new one and try once more
+	if ex.resp.StatusCode == http.StatusUnauthorized && call.oauth != nil && call.httpReq.Header.Get("Authorization") == call.authorization {
+		ex.discard()
+		fresh, err := c.authorization(ctx, call.httpClient, call.service, call.oauth, call.authorization)
+		if err != nil {
+			return nil, attempts, err
+		}
+		retryReq, err := rewind(ctx, call.httpReq)
+		if err != nil {
+			return nil, attempts, err
+		}
+		retryReq.Header.Set("Authorization", fresh)
+		if err := c.sign(call.service, retryReq); err != nil {
+			return nil, attempts, err
+		}
+
+		var more []Attempt
+		if ex, more, err = exchangeWithRetries(ctx, call.httpClient, retryReq, call.policy, maxBody); err != nil {
+			return nil, append(attempts, more...), err
+		}
+		attempts = append(attempts, more...)
 	}
+	return ex, attempts, nil
+}
 
-	return resp, nil
+// stopHeaderTimer disarms a streaming call's response timeout once headers are in
+func (call *upstreamCall) stopHeaderTimer() {
+	if call.headerTimer != nil {
+		call.headerTimer.Stop()
+	}
+}
+
+// resolveBodyMode decides how to encode the request body and which media type to declare
+// An explicit BodyMode wins, then the caller's Content-Type, then the spec's requestBody.content
+func (c *Client) resolveBodyMode(spec *serviceSpec, req *Request) (mode, mediaType string) {
+	if req.BodyMode != "" {
+		return req.BodyMode, ""
+	}
+
+	for key, value := range req.Headers {
+		if strings.EqualFold(key, "Content-Type") {
+			if mode := bodyModeForMediaType(value); mode != "" {
+				return mode, ""
+			}
+		}
+	}
+
+	if len(req.Body) == 0 && len(req.Parts) == 0 {
+		return BodyModeJSON, ""
+	}
+
+	if op, ok := findOperation(spec, req.Method, req.Path); ok {
+		for _, mediaType := range requestBodyMediaTypes(spec.doc, op) {
+			if strings.Contains(mediaType, "*") {
+				continue
+			}
+			return bodyModeForMediaType(mediaType), mediaType
+		}
+	}
+
+	// Parts without any other hint are a form; files need multipart
+	for _, part := range req.Parts {
+		if part.Filename != "" {
+			return BodyModeMultipart, ""
+		}
+	}
+	if len(req.Parts) > 0 {
+		return BodyModeForm, ""
+	}
+
+	return BodyModeJSON, ""
 }
 
 // isValidHTTPMethod checks if the method is a valid HTTP method
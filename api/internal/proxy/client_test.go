@@ -1,10 +1,13 @@
 package proxy
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"jonathanmcclement.com/playground/internal/storage"
@@ -12,7 +15,9 @@ import (
 
 // mockSpecStore implements storage.SpecStore for testing
 type mockSpecStore struct {
+	specs   map[string]json.RawMessage
 	configs map[string]*storage.ServiceConfig
+	gets    atomic.Int32 // Calls to Get, i.e. spec decodes
 }
 
 func (m *mockSpecStore) List() ([]string, error) {
@@ -20,7 +25,20 @@ func (m *mockSpecStore) List() ([]string, error) {
 }
 
 func (m *mockSpecStore) Get(serviceName string) (json.RawMessage, error) {
-	return nil, nil
+	m.gets.Add(1)
+	spec, exists := m.specs[serviceName]
+	if !exists {
+		return nil, storage.ErrServiceNotFound
+	}
+	return spec, nil
+}
+
+func (m *mockSpecStore) GetOriginal(serviceName string) (json.RawMessage, error) {
+	return m.Get(serviceName)
+}
+
+func (m *mockSpecStore) Diagnostics(serviceName string) ([]storage.Diagnostic, error) {
+	return []storage.Diagnostic{}, nil
 }
 
 func (m *mockSpecStore) GetConfig(serviceName string) (*storage.ServiceConfig, error) {
@@ -64,7 +82,7 @@ func TestClient_Forward_BasicGET(t *testing.T) {
 		Path:    "/test",
 	}
 
-	resp, err := client.Forward(req)
+	resp, err := client.Forward(context.Background(), req)
 	if err != nil {
 		t.Fatalf("Forward() failed: %v", err)
 	}
@@ -133,7 +151,7 @@ func TestClient_Forward_POST_WithBody(t *testing.T) {
 		Body:    reqBody,
 	}
 
-	resp, err := client.Forward(req)
+	resp, err := client.Forward(context.Background(), req)
 	if err != nil {
 		t.Fatalf("Forward() failed: %v", err)
 	}
@@ -179,7 +197,7 @@ func TestClient_Forward_AuthHeaders(t *testing.T) {
 		Path:    "/secure",
 	}
 
-	resp, err := client.Forward(req)
+	resp, err := client.Forward(context.Background(), req)
 	if err != nil {
 		t.Fatalf("Forward() failed: %v", err)
 	}
@@ -189,6 +207,63 @@ func TestClient_Forward_AuthHeaders(t *testing.T) {
 	}
 }
 
+func TestClient_Forward_Environment(t *testing.T) {
+	var gotAuth string
+	newBackend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = r.Header.Get("Authorization")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`"` + name + `"`))
+		}))
+	}
+	dev := newBackend("dev")
+	defer dev.Close()
+	prod := newBackend("prod")
+	defer prod.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"multi": {
+				DefaultEnvironment: "dev",
+				Environments: map[string]*storage.EnvironmentConfig{
+					"dev":  {BaseURL: dev.URL},
+					"prod": {BaseURL: prod.URL, AuthHeaders: map[string]string{"Authorization": "Bearer prod"}},
+				},
+			},
+		},
+	}
+
+	client := NewClient(store)
+
+	tests := []struct {
+		environment string
+		wantBody    string
+		wantAuth    string
+	}{
+		{environment: "", wantBody: `"dev"`},
+		{environment: "prod", wantBody: `"prod"`, wantAuth: "Bearer prod"},
+	}
+
+	for _, tt := range tests {
+		gotAuth = ""
+		resp, err := client.Forward(context.Background(), &Request{Service: "multi", Method: http.MethodGet, Path: "/", Environment: tt.environment})
+		if err != nil {
+			t.Fatalf("%q: Forward() failed: %v", tt.environment, err)
+		}
+		if string(resp.Body) != tt.wantBody {
+			t.Errorf("%q: expected body %s, got %s", tt.environment, tt.wantBody, resp.Body)
+		}
+		if gotAuth != tt.wantAuth {
+			t.Errorf("%q: expected Authorization %q, got %q", tt.environment, tt.wantAuth, gotAuth)
+		}
+	}
+
+	_, err := client.Forward(context.Background(), &Request{Service: "multi", Method: http.MethodGet, Path: "/", Environment: "qa"})
+	if !errors.Is(err, storage.ErrEnvironmentNotFound) {
+		t.Errorf("expected ErrEnvironmentNotFound, got %v", err)
+	}
+}
+
 func TestClient_Forward_HeaderOverride(t *testing.T) {
 	// Create mock backend
 	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -224,7 +299,7 @@ func TestClient_Forward_HeaderOverride(t *testing.T) {
 		},
 	}
 
-	resp, err := client.Forward(req)
+	resp, err := client.Forward(context.Background(), req)
 	if err != nil {
 		t.Fatalf("Forward() failed: %v", err)
 	}
@@ -247,9 +322,9 @@ func TestClient_Forward_ServiceNotFound(t *testing.T) {
 		Path:    "/test",
 	}
 
-	_, err := client.Forward(req)
-	if err == nil {
-		t.Fatal("expected error for nonexistent service, got nil")
+	_, err := client.Forward(context.Background(), req)
+	if !errors.Is(err, ErrServiceNotFound) {
+		t.Fatalf("expected ErrServiceNotFound, got %v", err)
 	}
 }
 
@@ -270,9 +345,9 @@ func TestClient_Forward_InvalidMethod(t *testing.T) {
 		Path:    "/test",
 	}
 
-	_, err := client.Forward(req)
-	if err == nil {
-		t.Fatal("expected error for invalid HTTP method, got nil")
+	_, err := client.Forward(context.Background(), req)
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest, got %v", err)
 	}
 }
 
@@ -302,7 +377,7 @@ func TestClient_Forward_ResponseHeaders(t *testing.T) {
 		Path:    "/test",
 	}
 
-	resp, err := client.Forward(req)
+	resp, err := client.Forward(context.Background(), req)
 	if err != nil {
 		t.Fatalf("Forward() failed: %v", err)
 	}
@@ -318,6 +393,44 @@ func TestClient_Forward_ResponseHeaders(t *testing.T) {
 	}
 }
 
+func TestClient_Forward_DecodesSpecOnce(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer backend.Close()
+
+	// No proxy config, so the target, body mode, request and response validation all need the spec
+	store := &mockSpecStore{specs: map[string]json.RawMessage{
+		"test-service": json.RawMessage(`{
+			"openapi": "3.0.0",
+			"servers": [{"url": "` + backend.URL + `"}],
+			"paths": {"/pets": {"post": {
+				"requestBody": {"content": {"application/json": {"schema": {"type": "object"}}}},
+				"responses": {"201": {"description": "created"}}
+			}}}
+		}`),
+	}}
+	client := NewClient(store, WithRequestValidation(true))
+
+	resp, err := client.Forward(context.Background(), &Request{
+		Service: "test-service",
+		Method:  http.MethodPost,
+		Path:    "/pets",
+		Body:    json.RawMessage(`{"name": "rex"}`),
+	})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	if resp.Validation == nil || !resp.Validation.Valid {
+		t.Errorf("expected a valid response validation, got %+v", resp.Validation)
+	}
+	if gets := store.gets.Load(); gets != 1 {
+		t.Errorf("expected the spec to be loaded once, got %d loads", gets)
+	}
+}
+
 func TestIsValidHTTPMethod(t *testing.T) {
 	validMethods := []string{
 		http.MethodGet,
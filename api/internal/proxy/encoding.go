@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Body encodings used in Response.BodyEncoding
+const (
+	BodyEncodingJSON   = "json"   // Body is the upstream JSON document as-is
+	BodyEncodingText   = "text"   // Body is a JSON string holding the upstream text
+	BodyEncodingBase64 = "base64" // Body is a JSON string holding the base64-encoded upstream bytes
+)
+
+// encodeBody picks an encoding for an upstream body so it always fits in the JSON envelope
+// The declared Content-Type is preferred; content sniffing is used when it is missing
+func encodeBody(contentType string, body []byte) (json.RawMessage, string, error) {
+	if len(body) == 0 {
+		return json.RawMessage(`""`), BodyEncodingText, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+		// DetectContentType reports JSON as text/plain, so check validity first
+		if json.Valid(body) {
+			return json.RawMessage(body), BodyEncodingJSON, nil
+		}
+	}
+
+	switch {
+	case isJSONMediaType(mediaType) && json.Valid(body):
+		return json.RawMessage(body), BodyEncodingJSON, nil
+	case isTextMediaType(mediaType) && utf8.Valid(body):
+		return marshalString(string(body), BodyEncodingText)
+	default:
+		return marshalString(base64.StdEncoding.EncodeToString(body), BodyEncodingBase64)
+	}
+}
+
+// marshalString wraps a string as a JSON string value with the given encoding
+func marshalString(s, encoding string) (json.RawMessage, string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, "", err
+	}
+	return json.RawMessage(data), encoding, nil
+}
+
+// isJSONMediaType reports whether a media type is JSON or a +json structured syntax
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isTextMediaType reports whether a media type carries human-readable text
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") || isJSONMediaType(mediaType) {
+		return true
+	}
+	if strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/xml",
+		"application/javascript",
+		"application/x-www-form-urlencoded",
+		"application/graphql",
+		"application/yaml",
+		"application/x-yaml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func TestEncodeBody(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		name         string
+		contentType  string
+		body         []byte
+		wantEncoding string
+		wantBody     string
+	}{
+		{"json", "application/json", []byte(`{"ok":true}`), BodyEncodingJSON, `{"ok":true}`},
+		{"problem json", "application/problem+json; charset=utf-8", []byte(`{"title":"x"}`), BodyEncodingJSON, `{"title":"x"}`},
+		{"invalid json", "application/json", []byte(`not json`), BodyEncodingText, "not json"},
+		{"html", "text/html; charset=utf-8", []byte("<h1>Bad Gateway</h1>"), BodyEncodingText, "<h1>Bad Gateway</h1>"},
+		{"xml", "application/xml", []byte("<a>1</a>"), BodyEncodingText, "<a>1</a>"},
+		{"image", "image/png", png, BodyEncodingBase64, base64.StdEncoding.EncodeToString(png)},
+		{"sniffed json", "", []byte(`[1,2]`), BodyEncodingJSON, `[1,2]`},
+		{"sniffed text", "", []byte("plain words"), BodyEncodingText, "plain words"},
+		{"sniffed binary", "", png, BodyEncodingBase64, base64.StdEncoding.EncodeToString(png)},
+		{"empty", "application/json", nil, BodyEncodingText, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, encoding, err := encodeBody(tt.contentType, tt.body)
+			if err != nil {
+				t.Fatalf("encodeBody() failed: %v", err)
+			}
+
+			if encoding != tt.wantEncoding {
+				t.Errorf("expected encoding %q, got %q", tt.wantEncoding, encoding)
+			}
+
+			if !json.Valid(body) {
+				t.Fatalf("expected valid JSON body, got %s", body)
+			}
+
+			got := string(body)
+			if encoding != BodyEncodingJSON {
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Fatalf("failed to unmarshal string body: %v", err)
+				}
+			}
+
+			if got != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// Failure classes returned by Forward; match them with errors.Is
+var (
+	// ErrInvalidRequest is returned when the request itself is malformed, e.g. a bad method,
+	// an undecodable body, or an unknown environment or server
+	ErrInvalidRequest = errors.New("invalid proxy request")
+
+	// ErrServiceNotFound is returned when no spec exists for the requested service
+	ErrServiceNotFound = errors.New("service not found")
+
+	// ErrTargetDenied is returned when the backend address is in a denied network range
+	ErrTargetDenied = errors.New("target address denied")
+
+	// ErrTokenRequest is returned when an OAuth2 access token for the service cannot be obtained
+	ErrTokenRequest = errors.New("failed to obtain access token")
+
+	// ErrUpstreamTimeout is returned when the backend does not answer in time
+	ErrUpstreamTimeout = errors.New("upstream timed out")
+
+	// ErrCanceled is returned when the caller gives up on the request, e.g. the browser aborts it
+	ErrCanceled = errors.New("request canceled")
+
+	// ErrUpstreamDNS is returned when the backend host name cannot be resolved
+	ErrUpstreamDNS = errors.New("upstream host not found")
+
+	// ErrUpstreamRefused is returned when the backend actively refuses the connection
+	ErrUpstreamRefused = errors.New("upstream refused connection")
+
+	// ErrUpstreamUnreachable is returned for other network failures, e.g. resets or TLS errors
+	ErrUpstreamUnreachable = errors.New("upstream unreachable")
+
+	// ErrUpstreamResponse is returned when the backend's response cannot be read
+	ErrUpstreamResponse = errors.New("failed to read upstream response")
+)
+
+// invalidRequest marks err as caused by the caller's input
+func invalidRequest(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %w", ErrInvalidRequest, fmt.Errorf(format, args...))
+}
+
+// classifyTransportError wraps an error from exchanging the upstream request in its failure class
+// ctx is the request's context; once it is done its error decides between timeout and cancellation
+func classifyTransportError(ctx context.Context, err error) error {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+
+	// Streaming calls end their header timeout by canceling with DeadlineExceeded as the cause
+	cause := context.Cause(ctx)
+
+	switch {
+	case errors.Is(cause, context.Canceled):
+		return fmt.Errorf("%w: %w", ErrCanceled, err)
+	case errors.Is(err, ErrTargetDenied):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(cause, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", ErrUpstreamTimeout, err)
+	case errors.As(err, &dnsErr):
+		return fmt.Errorf("%w: %w", ErrUpstreamDNS, err)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("%w: %w", ErrUpstreamRefused, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrUpstreamUnreachable, err)
+	}
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+func TestClassifyTransportError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "deadline exceeded",
+			err:  &url.Error{Op: "Get", URL: "http://api.test", Err: context.DeadlineExceeded},
+			want: ErrUpstreamTimeout,
+		},
+		{
+			name: "dial timeout",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			want: ErrUpstreamTimeout,
+		},
+		{
+			name: "unknown host",
+			err:  &url.Error{Op: "Get", URL: "http://nowhere.test", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "nowhere.test", IsNotFound: true}}},
+			want: ErrUpstreamDNS,
+		},
+		{
+			name: "connection refused",
+			err:  &url.Error{Op: "Get", URL: "http://127.0.0.1:1", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+			want: ErrUpstreamRefused,
+		},
+		{
+			name: "connection reset",
+			err:  &url.Error{Op: "Get", URL: "http://api.test", Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}},
+			want: ErrUpstreamUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyTransportError(context.Background(), tt.err)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected the original error to stay wrapped, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClassifyTransportError_Context(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := classifyTransportError(canceled, errors.New("read: connection closed")); !errors.Is(err, ErrCanceled) {
+		t.Errorf("expected ErrCanceled for a canceled context, got %v", err)
+	}
+
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if err := classifyTransportError(expired, errors.New("read: connection closed")); !errors.Is(err, ErrUpstreamTimeout) {
+		t.Errorf("expected ErrUpstreamTimeout for an expired context, got %v", err)
+	}
+}
+
+func TestClient_Forward_ConnectionRefused(t *testing.T) {
+	// Grab a free port, then close the listener so nothing answers on it
+	backend := httptest.NewServer(http.NotFoundHandler())
+	baseURL := backend.URL
+	backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: baseURL},
+		},
+	}
+
+	_, err := NewClient(store).Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: "/test"})
+	if !errors.Is(err, ErrUpstreamRefused) {
+		t.Fatalf("expected ErrUpstreamRefused, got %v", err)
+	}
+}
+
+func TestValidationError_IsInvalidRequest(t *testing.T) {
+	err := error(&ValidationError{Violations: []Violation{{Pointer: "/body", Message: "request body is required"}}})
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("expected ValidationError to match ErrInvalidRequest")
+	}
+}
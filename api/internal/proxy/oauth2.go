@@ -0,0 +1,171 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+const (
+	// tokenExpirySkew renews tokens this long before they expire so requests in flight never carry a stale one
+	tokenExpirySkew = 30 * time.Second
+
+	// maxTokenResponseSize bounds how much of a token endpoint's response is read
+	maxTokenResponseSize = 1 << 20
+)
+
+// oauthToken is an access token and when it stops being valid (zero when the server did not say)
+type oauthToken struct {
+	authorization string // Ready-to-send Authorization header value
+	expiry        time.Time
+}
+
+// fresh reports whether the token can still be sent
+func (t *oauthToken) fresh(now time.Time) bool {
+	return t != nil && (t.expiry.IsZero() || now.Add(tokenExpirySkew).Before(t.expiry))
+}
+
+// tokenEntry caches one service's token; mu serializes fetches so concurrent requests share one
+type tokenEntry struct {
+	mu           sync.Mutex
+	config       *storage.OAuth2Config
+	token        *oauthToken
+	refreshToken string // Rotated when the token endpoint issues a new one
+}
+
+// tokenCache holds one entry per service, replaced when a reload yields a new OAuth2 config
+type tokenCache struct {
+	mu      sync.Mutex
+	entries map[string]*tokenEntry
+}
+
+// tokenResponse is the token endpoint's JSON answer, success or error (RFC 6749 5.1 and 5.2)
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	RefreshToken     string      `json:"refresh_token"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// oauthConfig returns the service's OAuth2 settings, or nil when it has none
+func (c *Client) oauthConfig(service string) *storage.OAuth2Config {
+	config, err := c.store.GetConfig(service)
+	if err != nil || config == nil {
+		return nil
+	}
+	return config.OAuth2
+}
+
+// authorization returns an Authorization header value for the service, fetching a token
+// when none is cached or the cached one is about to expire. A non-empty rejected value
+// forces a new token unless another request already replaced the rejected one.
+func (c *Client) authorization(ctx context.Context, client *http.Client, service string, config *storage.OAuth2Config, rejected string) (string, error) {
+	c.tokens.mu.Lock()
+	entry, ok := c.tokens.entries[service]
+	if !ok || entry.config != config {
+		entry = &tokenEntry{config: config, refreshToken: config.RefreshToken}
+		c.tokens.entries[service] = entry
+	}
+	c.tokens.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.token.fresh(time.Now()) && entry.token.authorization != rejected {
+		return entry.token.authorization, nil
+	}
+
+	token, refreshToken, err := fetchToken(ctx, client, config, entry.refreshToken)
+	if err != nil {
+		entry.token = nil
+		return "", err
+	}
+	entry.token = token
+	if refreshToken != "" {
+		entry.refreshToken = refreshToken
+	}
+	return token.authorization, nil
+}
+
+// fetchToken requests an access token with the config's grant and returns it with any
+// rotated refresh token. Errors never include the credentials or the token.
+func fetchToken(ctx context.Context, client *http.Client, config *storage.OAuth2Config, refreshToken string) (*oauthToken, string, error) {
+	form := url.Values{}
+	if config.GrantType() == storage.GrantRefreshToken {
+		form.Set("grant_type", storage.GrantRefreshToken)
+		form.Set("refresh_token", refreshToken)
+	} else {
+		form.Set("grant_type", storage.GrantClientCredentials)
+	}
+	if len(config.Scopes) > 0 {
+		form.Set("scope", strings.Join(config.Scopes, " "))
+	}
+	if config.ClientAuth == storage.ClientAuthBody {
+		form.Set("client_id", config.ClientID)
+		if config.ClientSecret != "" {
+			form.Set("client_secret", config.ClientSecret)
+		}
+	}
+
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPost, config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrTokenRequest, err)
+	}
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	tokenReq.Header.Set("Accept", "application/json")
+	if config.ClientAuth != storage.ClientAuthBody {
+		// RFC 6749 2.3.1 form-encodes the credentials before Basic encoding
+		tokenReq.SetBasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret))
+	}
+
+	tokenResp, err := client.Do(tokenReq)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrTokenRequest, classifyTransportError(ctx, err))
+	}
+	defer tokenResp.Body.Close()
+
+	var parsed tokenResponse
+	data, err := io.ReadAll(io.LimitReader(tokenResp.Body, maxTokenResponseSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrTokenRequest, classifyTransportError(ctx, err))
+	}
+	decodeErr := json.Unmarshal(data, &parsed)
+
+	if tokenResp.StatusCode < 200 || tokenResp.StatusCode > 299 {
+		if parsed.Error != "" {
+			return nil, "", fmt.Errorf("%w: token endpoint returned %d: %s", ErrTokenRequest, tokenResp.StatusCode, parsed.Error)
+		}
+		return nil, "", fmt.Errorf("%w: token endpoint returned %d", ErrTokenRequest, tokenResp.StatusCode)
+	}
+	if decodeErr != nil {
+		return nil, "", fmt.Errorf("%w: invalid token response: %w", ErrTokenRequest, decodeErr)
+	}
+	if parsed.AccessToken == "" {
+		return nil, "", fmt.Errorf("%w: token response has no access_token", ErrTokenRequest)
+	}
+
+	token := &oauthToken{authorization: tokenAuthorization(parsed.TokenType, parsed.AccessToken)}
+	if seconds, err := strconv.ParseInt(parsed.ExpiresIn.String(), 10, 64); err == nil && seconds > 0 {
+		token.expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return token, parsed.RefreshToken, nil
+}
+
+// tokenAuthorization builds the Authorization value; bearer is normalized since servers vary its case
+func tokenAuthorization(tokenType, accessToken string) string {
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + accessToken
+}
@@ -0,0 +1,268 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// tokenServer is a minimal OAuth2 token endpoint that issues tok-1, tok-2, ... and records each request
+type tokenServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	requests  []map[string]string
+	expiresIn int
+	status    int
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	t.Helper()
+	ts := &tokenServer{expiresIn: expiresIn, status: http.StatusOK}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("token request is not a form: %v", err)
+		}
+		seen := map[string]string{}
+		for key := range r.PostForm {
+			seen[key] = r.PostForm.Get(key)
+		}
+		if id, secret, ok := r.BasicAuth(); ok {
+			seen["basic"] = id + ":" + secret
+		}
+
+		ts.mu.Lock()
+		ts.requests = append(ts.requests, seen)
+		n := len(ts.requests)
+		status := ts.status
+		ts.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error":"invalid_client","error_description":"bad secret"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("tok-%d", n),
+			"token_type":    "bearer",
+			"expires_in":    ts.expiresIn,
+			"refresh_token": fmt.Sprintf("rt-%d", n),
+		})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func (ts *tokenServer) calls() []map[string]string {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return append([]map[string]string(nil), ts.requests...)
+}
+
+// authBackend answers 401 unless the Authorization header is one of the accepted values
+func authBackend(t *testing.T, accepted ...string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var seen []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		mu.Lock()
+		seen = append(seen, auth)
+		mu.Unlock()
+		for _, value := range accepted {
+			if auth == value {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"ok":true}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(backend.Close)
+	return backend, &seen
+}
+
+func TestClient_Forward_OAuth2ClientCredentials(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	backend, seen := authBackend(t, "Bearer tok-1")
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"billing": {
+				BaseURL:     backend.URL,
+				AuthHeaders: map[string]string{"Authorization": "Bearer static"},
+				OAuth2: &storage.OAuth2Config{
+					TokenURL:     tokens.URL,
+					ClientID:     "playground",
+					ClientSecret: "s3cret",
+					Scopes:       []string{"billing.read", "billing.write"},
+				},
+			},
+		},
+	}
+	client := NewClient(store)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Forward(context.Background(), &Request{Service: "billing", Method: http.MethodGet, Path: "/invoices"})
+		if err != nil {
+			t.Fatalf("Forward() failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.StatusCode)
+		}
+		envelope, _ := json.Marshal(resp)
+		if strings.Contains(string(envelope), "tok-1") {
+			t.Error("expected the access token to stay out of the response envelope")
+		}
+	}
+
+	calls := tokens.calls()
+	if len(calls) != 1 {
+		t.Fatalf("expected the token to be fetched once and cached, got %d fetches", len(calls))
+	}
+	if calls[0]["grant_type"] != "client_credentials" || calls[0]["scope"] != "billing.read billing.write" {
+		t.Errorf("unexpected token request %v", calls[0])
+	}
+	if calls[0]["basic"] != "playground:s3cret" || calls[0]["client_secret"] != "" {
+		t.Errorf("expected Basic client authentication only, got %v", calls[0])
+	}
+	if len(*seen) != 2 || (*seen)[0] != "Bearer tok-1" {
+		t.Errorf("expected the token to replace the static header, got %v", *seen)
+	}
+}
+
+func TestClient_Forward_OAuth2RefreshBeforeExpiry(t *testing.T) {
+	// Tokens that expire inside the renewal window are never reused
+	tokens := newTokenServer(t, 10)
+	backend, seen := authBackend(t, "Bearer tok-1", "Bearer tok-2")
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"billing": {
+				BaseURL: backend.URL,
+				OAuth2: &storage.OAuth2Config{
+					TokenURL:     tokens.URL,
+					ClientID:     "playground",
+					RefreshToken: "rt-0",
+					ClientAuth:   storage.ClientAuthBody,
+				},
+			},
+		},
+	}
+	client := NewClient(store)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Forward(context.Background(), &Request{Service: "billing", Method: http.MethodGet, Path: "/invoices"}); err != nil {
+			t.Fatalf("Forward() failed: %v", err)
+		}
+	}
+
+	calls := tokens.calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected a fetch per request, got %d", len(calls))
+	}
+	if calls[0]["grant_type"] != "refresh_token" || calls[0]["refresh_token"] != "rt-0" {
+		t.Errorf("expected the configured refresh token first, got %v", calls[0])
+	}
+	if calls[1]["refresh_token"] != "rt-1" {
+		t.Errorf("expected the rotated refresh token, got %v", calls[1])
+	}
+	if calls[0]["client_id"] != "playground" || calls[0]["basic"] != "" {
+		t.Errorf("expected client credentials in the body, got %v", calls[0])
+	}
+	if (*seen)[1] != "Bearer tok-2" {
+		t.Errorf("expected the second request to use the new token, got %v", *seen)
+	}
+}
+
+func TestClient_Forward_OAuth2RetryOnUnauthorized(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	// The first token is revoked before it expires
+	backend, seen := authBackend(t, "Bearer tok-2")
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"billing": {
+				BaseURL: backend.URL,
+				OAuth2:  &storage.OAuth2Config{TokenURL: tokens.URL, ClientID: "playground", ClientSecret: "s3cret"},
+			},
+		},
+	}
+
+	resp, err := NewClient(store).Forward(context.Background(), &Request{
+		Service: "billing",
+		Method:  http.MethodPost,
+		Path:    "/invoices",
+		Body:    []byte(`{"amount":1}`),
+	})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the retry with a new token to succeed, got %d", resp.StatusCode)
+	}
+	if len(tokens.calls()) != 2 || len(*seen) != 2 {
+		t.Errorf("expected one retry, got %d token fetches and backend calls %v", len(tokens.calls()), *seen)
+	}
+}
+
+func TestClient_Forward_OAuth2NoRetryForCallerAuthorization(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	backend, seen := authBackend(t)
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"billing": {
+				BaseURL: backend.URL,
+				OAuth2:  &storage.OAuth2Config{TokenURL: tokens.URL, ClientID: "playground"},
+			},
+		},
+	}
+
+	resp, err := NewClient(store).Forward(context.Background(), &Request{
+		Service: "billing",
+		Method:  http.MethodGet,
+		Path:    "/invoices",
+		Headers: map[string]string{"Authorization": "Bearer mine"},
+	})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized || len(*seen) != 1 || (*seen)[0] != "Bearer mine" {
+		t.Errorf("expected the caller's header to be sent once, got %d and %v", resp.StatusCode, *seen)
+	}
+}
+
+func TestClient_Forward_OAuth2TokenError(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+	tokens.status = http.StatusUnauthorized
+	backend, seen := authBackend(t)
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"billing": {
+				BaseURL: backend.URL,
+				OAuth2:  &storage.OAuth2Config{TokenURL: tokens.URL, ClientID: "playground", ClientSecret: "s3cret"},
+			},
+		},
+	}
+
+	_, err := NewClient(store).Forward(context.Background(), &Request{Service: "billing", Method: http.MethodGet, Path: "/invoices"})
+	if !errors.Is(err, ErrTokenRequest) {
+		t.Fatalf("expected ErrTokenRequest, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "invalid_client") || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("expected the OAuth2 error code without credentials, got %v", err)
+	}
+	if len(*seen) != 0 {
+		t.Error("expected the backend not to be called without a token")
+	}
+}
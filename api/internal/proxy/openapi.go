@@ -0,0 +1,239 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// operation is the spec entry that matches a proxied request
+type operation struct {
+	pathTemplate string                 // Path key in the spec, e.g. /pets/{id}
+	pathItem     map[string]interface{} // Path item holding shared parameters
+	op           map[string]interface{} // Operation object for the method
+	pathParams   map[string]string      // Values captured from the request path
+}
+
+// pathParamPattern matches templated segments such as {id}
+var pathParamPattern = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// serviceSpec is a decoded spec with the patterns of its templated path segments compiled
+// It is shared by every request to the service and must not be modified.
+type serviceSpec struct {
+	doc      map[string]interface{}
+	segments map[string]*regexp.Regexp // Templated segment, e.g. {name}.{ext}, to its pattern; nil if it did not compile
+}
+
+// specCache keeps each service's decoded spec until the store serves a different document
+type specCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedSpec
+}
+
+type cachedSpec struct {
+	raw  json.RawMessage
+	spec *serviceSpec
+}
+
+// loadSpec fetches the public spec for a service, decoding and compiling it only when it changed
+// prepare calls it once per request and hands the result to everything that reads the spec.
+func (c *Client) loadSpec(service string) (*serviceSpec, error) {
+	raw, err := c.store.Get(service)
+
+	c.specs.mu.Lock()
+	defer c.specs.mu.Unlock()
+
+	if err != nil {
+		delete(c.specs.entries, service)
+		return nil, err
+	}
+
+	if cached, ok := c.specs.entries[service]; ok && bytes.Equal(cached.raw, raw) {
+		return cached.spec, nil
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		delete(c.specs.entries, service)
+		return nil, fmt.Errorf("invalid spec for service %s: %w", service, err)
+	}
+	spec := compileSpec(doc)
+	c.specs.entries[service] = &cachedSpec{raw: raw, spec: spec}
+	return spec, nil
+}
+
+// compileSpec builds the segment patterns for every templated path in a decoded spec
+func compileSpec(doc map[string]interface{}) *serviceSpec {
+	spec := &serviceSpec{doc: doc, segments: make(map[string]*regexp.Regexp)}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for template := range paths {
+		for _, seg := range strings.Split(strings.Trim(template, "/"), "/") {
+			if _, done := spec.segments[seg]; done || !pathParamPattern.MatchString(seg) {
+				continue
+			}
+			spec.segments[seg] = segmentPattern(seg)
+		}
+	}
+	return spec
+}
+
+// segmentPattern builds a regex like ^prefix(.+?)suffix$ for a templated path segment
+// A segment that does not compile yields nil, which matches nothing.
+func segmentPattern(seg string) *regexp.Regexp {
+	names := pathParamPattern.FindAllString(seg, -1)
+	literals := pathParamPattern.Split(seg, -1)
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for j, lit := range literals {
+		expr.WriteString(regexp.QuoteMeta(lit))
+		if j < len(names) {
+			expr.WriteString("(.+?)")
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// findOperation matches a method and request path against the spec's paths
+// Literal paths win over templated ones, e.g. /pets/mine beats /pets/{id}
+func findOperation(spec *serviceSpec, method, path string) (*operation, bool) {
+	if spec == nil {
+		return nil, false
+	}
+	paths, _ := spec.doc["paths"].(map[string]interface{})
+	if len(paths) == 0 {
+		return nil, false
+	}
+
+	// Ignore any query string the caller baked into the path
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	var best *operation
+	for template, item := range paths {
+		pathItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		op, ok := pathItem[strings.ToLower(method)].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		params, ok := matchPath(spec.segments, template, path)
+		if !ok {
+			continue
+		}
+
+		if best == nil || len(params) < len(best.pathParams) ||
+			(len(params) == len(best.pathParams) && template < best.pathTemplate) {
+			best = &operation{
+				pathTemplate: template,
+				pathItem:     pathItem,
+				op:           op,
+				pathParams:   params,
+			}
+		}
+	}
+
+	return best, best != nil
+}
+
+// matchPath matches a concrete path against a templated spec path
+// segments holds the compiled patterns of the template's templated segments, see compileSpec.
+func matchPath(segments map[string]*regexp.Regexp, template, path string) (map[string]string, bool) {
+	tSegs := strings.Split(strings.Trim(template, "/"), "/")
+	pSegs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(tSegs) != len(pSegs) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, tSeg := range tSegs {
+		pSeg := pSegs[i]
+
+		names := pathParamPattern.FindAllStringSubmatch(tSeg, -1)
+		if len(names) == 0 {
+			if tSeg != pSeg {
+				return nil, false
+			}
+			continue
+		}
+
+		re := segments[tSeg]
+		if re == nil {
+			return nil, false
+		}
+		m := re.FindStringSubmatch(pSeg)
+		if m == nil {
+			return nil, false
+		}
+		for j, name := range names {
+			value, err := url.PathUnescape(m[j+1])
+			if err != nil {
+				value = m[j+1]
+			}
+			params[name[1]] = value
+		}
+	}
+
+	return params, true
+}
+
+// resolveRef follows local $ref pointers (#/components/...) until it reaches a concrete node
+func resolveRef(spec map[string]interface{}, node interface{}) interface{} {
+	for depth := 0; depth < 32; depth++ {
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return node
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return node
+		}
+
+		var target interface{} = spec
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			m, ok := target.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			target = m[token]
+		}
+		node = target
+	}
+	return nil
+}
+
+// requestBodyMediaTypes lists the media types an operation accepts, JSON first
+func requestBodyMediaTypes(spec map[string]interface{}, op *operation) []string {
+	body, _ := resolveRef(spec, op.op["requestBody"]).(map[string]interface{})
+	content, _ := body["content"].(map[string]interface{})
+
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		ji, jj := isJSONMediaType(types[i]), isJSONMediaType(types[j])
+		if ji != jj {
+			return ji
+		}
+		return types[i] < types[j]
+	})
+	return types
+}
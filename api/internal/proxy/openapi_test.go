@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestFindOperation(t *testing.T) {
+	var spec map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"paths": {
+			"/pets": {"get": {"operationId": "listPets"}, "post": {"operationId": "createPet"}},
+			"/pets/{id}": {"get": {"operationId": "getPet"}},
+			"/pets/mine": {"get": {"operationId": "myPets"}},
+			"/files/{name}.{ext}": {"get": {"operationId": "getFile"}}
+		}
+	}`), &spec)
+	if err != nil {
+		t.Fatalf("failed to unmarshal spec: %v", err)
+	}
+
+	tests := []struct {
+		method     string
+		path       string
+		wantOpID   string
+		wantParams map[string]string
+	}{
+		{"GET", "/pets", "listPets", nil},
+		{"POST", "/pets?dryRun=true", "createPet", nil},
+		{"GET", "/pets/42", "getPet", map[string]string{"id": "42"}},
+		{"GET", "/pets/mine", "myPets", nil},
+		{"GET", "/pets/a%20b", "getPet", map[string]string{"id": "a b"}},
+		{"GET", "/files/report.pdf", "getFile", map[string]string{"name": "report", "ext": "pdf"}},
+		{"DELETE", "/pets/42", "", nil},
+		{"GET", "/owners", "", nil},
+	}
+
+	compiled := compileSpec(spec)
+	for _, tt := range tests {
+		op, ok := findOperation(compiled, tt.method, tt.path)
+		if tt.wantOpID == "" {
+			if ok {
+				t.Errorf("%s %s: expected no match, got %s", tt.method, tt.path, op.pathTemplate)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("%s %s: expected match %s, got none", tt.method, tt.path, tt.wantOpID)
+			continue
+		}
+
+		if op.op["operationId"] != tt.wantOpID {
+			t.Errorf("%s %s: expected operation %s, got %v", tt.method, tt.path, tt.wantOpID, op.op["operationId"])
+		}
+		for name, want := range tt.wantParams {
+			if op.pathParams[name] != want {
+				t.Errorf("%s %s: expected param %s=%q, got %q", tt.method, tt.path, name, want, op.pathParams[name])
+			}
+		}
+	}
+}
+
+func TestMatchPath_UncompiledSegment(t *testing.T) {
+	// A segment whose pattern failed to compile matches nothing instead of panicking
+	if _, ok := matchPath(map[string]*regexp.Regexp{"{id}": nil}, "/pets/{id}", "/pets/42"); ok {
+		t.Error("expected no match for a segment without a pattern")
+	}
+	if _, ok := matchPath(map[string]*regexp.Regexp{}, "/pets/{id}", "/pets/42"); ok {
+		t.Error("expected no match for a segment that was never compiled")
+	}
+}
+
+func TestClient_LoadSpec_Cached(t *testing.T) {
+	store := &mockSpecStore{specs: map[string]json.RawMessage{
+		"pets": json.RawMessage(`{"paths": {"/pets/{id}": {"get": {}}}}`),
+	}}
+	client := NewClient(store)
+
+	first, err := client.loadSpec("pets")
+	if err != nil {
+		t.Fatalf("loadSpec() failed: %v", err)
+	}
+	again, _ := client.loadSpec("pets")
+	if again != first {
+		t.Error("expected an unchanged spec to be decoded once")
+	}
+	if first.segments["{id}"] == nil {
+		t.Error("expected the {id} segment to be compiled")
+	}
+
+	store.specs["pets"] = json.RawMessage(`{"paths": {"/owners/{id}": {"get": {}}}}`)
+	changed, _ := client.loadSpec("pets")
+	if changed == first {
+		t.Fatal("expected a changed spec to be decoded again")
+	}
+	if _, ok := findOperation(changed, "GET", "/owners/1"); !ok {
+		t.Error("expected the new spec's paths to match")
+	}
+}
+
+func TestResolveRef(t *testing.T) {
+	var spec map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"components": {
+			"requestBodies": {"Pet": {"$ref": "#/components/requestBodies/PetBody"}, "PetBody": {"required": true}},
+			"schemas": {"a/b": {"type": "string"}}
+		}
+	}`), &spec)
+	if err != nil {
+		t.Fatalf("failed to unmarshal spec: %v", err)
+	}
+
+	body, ok := resolveRef(spec, map[string]interface{}{"$ref": "#/components/requestBodies/Pet"}).(map[string]interface{})
+	if !ok || body["required"] != true {
+		t.Errorf("expected chained ref to resolve, got %v", body)
+	}
+
+	schema, ok := resolveRef(spec, map[string]interface{}{"$ref": "#/components/schemas/a~1b"}).(map[string]interface{})
+	if !ok || schema["type"] != "string" {
+		t.Errorf("expected escaped ref to resolve, got %v", schema)
+	}
+
+	if resolveRef(spec, map[string]interface{}{"$ref": "#/components/schemas/missing"}) != nil {
+		t.Error("expected missing ref to resolve to nil")
+	}
+}
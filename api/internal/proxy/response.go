@@ -0,0 +1,145 @@
+package proxy
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseValidation reports how an upstream response compares to the spec operation
+// Violations use the same pointers as request validation: /status, /header/{name} and /body/...
+type ResponseValidation struct {
+	Operation  string      `json:"operation"` // Matched operation, e.g. GET /pets/{id}
+	Valid      bool        `json:"valid"`
+	Violations []Violation `json:"violations"`
+}
+
+// validateResponse checks an upstream response against the operation's documented responses
+// Returns nil when the spec is missing or has no operation for the request, since there is nothing to compare to
+func (c *Client) validateResponse(loaded *serviceSpec, req *Request, status int, header http.Header, body []byte) *ResponseValidation {
+	op, ok := findOperation(loaded, req.Method, req.Path)
+	if !ok {
+		return nil
+	}
+	spec := loaded.doc
+
+	v := &schemaValidator{spec: spec}
+	result := &ResponseValidation{
+		Operation: strings.ToUpper(req.Method) + " " + op.pathTemplate,
+	}
+
+	// 3.1 operations may leave responses out entirely, which documents nothing to check
+	_, declared := op.op["responses"]
+	response, documented := documentedResponse(spec, op, status)
+	switch {
+	case !declared:
+	case !documented:
+		v.addf("/status", "status %d is not documented for this operation", status)
+	default:
+		checkResponse(v, response, req.Method, header, body)
+	}
+
+	result.Violations = v.violations
+	if result.Violations == nil {
+		result.Violations = []Violation{}
+	}
+	result.Valid = len(result.Violations) == 0
+	return result
+}
+
+// documentedResponse finds the response object for a status: exact code, then range (2XX), then default
+func documentedResponse(spec map[string]interface{}, op *operation, status int) (map[string]interface{}, bool) {
+	responses, _ := op.op["responses"].(map[string]interface{})
+
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if raw, exists := responses[key]; exists {
+			response, _ := resolveRef(spec, raw).(map[string]interface{})
+			return response, true
+		}
+	}
+	return nil, false
+}
+
+// checkResponse compares headers, content type and JSON body with a response object
+func checkResponse(v *schemaValidator, response map[string]interface{}, method string, header http.Header, body []byte) {
+	headers, _ := response["headers"].(map[string]interface{})
+	for _, name := range sortedNames(headers) {
+		h, _ := resolveRef(v.spec, headers[name]).(map[string]interface{})
+		// Content-Type is described by content, not headers
+		if h == nil || strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+
+		values := header.Values(name)
+		pointer := "/header/" + escapeToken(name)
+		if len(values) == 0 {
+			if h["required"] == true {
+				v.addf(pointer, "missing required header %q", name)
+			}
+			continue
+		}
+		if schema, ok := h["schema"]; ok {
+			v.validate(schema, coerceParameter(v.spec, schema, h, values), pointer)
+		}
+	}
+
+	content, _ := response["content"].(map[string]interface{})
+	if len(content) == 0 || len(body) == 0 || method == http.MethodHead {
+		return
+	}
+
+	contentType := header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+
+	key, ok := matchMediaType(content, mediaType)
+	if !ok {
+		v.addf("/header/Content-Type", "content type %q is not documented, expected one of %s", contentType, strings.Join(sortedNames(content), ", "))
+		return
+	}
+
+	media, _ := content[key].(map[string]interface{})
+	schema, ok := media["schema"]
+	if !ok || !isJSONMediaType(mediaType) {
+		return
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		v.addf("/body", "invalid JSON: %v", err)
+		return
+	}
+	v.validate(schema, value, "/body")
+}
+
+// matchMediaType finds the content key for a media type: exact, then type/*, then */*
+func matchMediaType(content map[string]interface{}, mediaType string) (string, bool) {
+	if mediaType == "" {
+		return "", false
+	}
+
+	candidates := []string{mediaType}
+	if i := strings.IndexByte(mediaType, '/'); i > 0 {
+		candidates = append(candidates, mediaType[:i]+"/*")
+	}
+	candidates = append(candidates, "*/*")
+
+	for _, candidate := range candidates {
+		for key := range content {
+			// Keys may carry parameters, e.g. application/json; charset=utf-8
+			base, _, err := mime.ParseMediaType(key)
+			if err != nil {
+				base = key
+			}
+			if strings.EqualFold(base, candidate) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
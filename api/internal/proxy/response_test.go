@@ -0,0 +1,136 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+func TestClient_Forward_ResponseValidation(t *testing.T) {
+	type reply struct {
+		status      int
+		contentType string
+		headers     map[string]string
+		body        string
+	}
+	var next reply
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range next.headers {
+			w.Header().Set(name, value)
+		}
+		if next.contentType != "" {
+			w.Header().Set("Content-Type", next.contentType)
+		}
+		w.WriteHeader(next.status)
+		_, _ = w.Write([]byte(next.body))
+	}))
+	defer backend.Close()
+
+	spec := `{
+		"openapi": "3.0.0",
+		"paths": {
+			"/pets/{id}": {"get": {"responses": {
+				"200": {
+					"headers": {"X-Rate-Limit": {"required": true, "schema": {"type": "integer"}}},
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+				},
+				"4XX": {"content": {"application/problem+json": {"schema": {"type": "object", "required": ["title"]}}}}
+			}}}
+		},
+		"components": {"schemas": {"Pet": {
+			"type": "object",
+			"required": ["id", "name", "password"],
+			"properties": {"id": {"type": "integer"}, "name": {"type": "string"}, "password": {"type": "string", "writeOnly": true}}
+		}}}
+	}`
+
+	store := &mockSpecStore{
+		specs:   map[string]json.RawMessage{"pets": json.RawMessage(spec)},
+		configs: map[string]*storage.ServiceConfig{"pets": {BaseURL: backend.URL}},
+	}
+	client := NewClient(store)
+
+	tests := []struct {
+		name  string
+		path  string
+		reply reply
+		want  []string // Violation pointers; nil means valid
+		none  bool     // No operation matched, so no validation section
+	}{
+		{
+			name:  "matches contract",
+			path:  "/pets/1",
+			reply: reply{status: 200, contentType: "application/json; charset=utf-8", headers: map[string]string{"X-Rate-Limit": "10"}, body: `{"id":1,"name":"Rex"}`},
+		},
+		{
+			name:  "body and header drift",
+			path:  "/pets/1",
+			reply: reply{status: 200, contentType: "application/json", headers: map[string]string{"X-Rate-Limit": "lots"}, body: `{"id":"1"}`},
+			want:  []string{"/header/X-Rate-Limit", "/body/name", "/body/id"},
+		},
+		{
+			name:  "undocumented content type",
+			path:  "/pets/1",
+			reply: reply{status: 200, contentType: "text/html", headers: map[string]string{"X-Rate-Limit": "10"}, body: `<html></html>`},
+			want:  []string{"/header/Content-Type"},
+		},
+		{
+			name:  "status range",
+			path:  "/pets/1",
+			reply: reply{status: 404, contentType: "application/problem+json", body: `{}`},
+			want:  []string{"/body/title"},
+		},
+		{
+			name:  "undocumented status",
+			path:  "/pets/1",
+			reply: reply{status: 500, contentType: "text/plain", body: `boom`},
+			want:  []string{"/status"},
+		},
+		{
+			name:  "no operation",
+			path:  "/owners",
+			reply: reply{status: 200},
+			none:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next = tt.reply
+
+			resp, err := client.Forward(context.Background(), &Request{Service: "pets", Method: http.MethodGet, Path: tt.path})
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+
+			if tt.none {
+				if resp.Validation != nil {
+					t.Errorf("expected no validation section, got %+v", resp.Validation)
+				}
+				return
+			}
+			if resp.Validation == nil {
+				t.Fatal("expected validation section")
+			}
+			if resp.Validation.Operation != "GET /pets/{id}" {
+				t.Errorf("expected operation GET /pets/{id}, got %s", resp.Validation.Operation)
+			}
+			if resp.Validation.Valid != (len(tt.want) == 0) {
+				t.Errorf("expected valid=%v, got %+v", len(tt.want) == 0, resp.Validation)
+			}
+			if len(resp.Validation.Violations) != len(tt.want) {
+				t.Fatalf("expected %d violations, got %+v", len(tt.want), resp.Validation.Violations)
+			}
+			for i, pointer := range tt.want {
+				if resp.Validation.Violations[i].Pointer != pointer {
+					t.Errorf("violation %d: expected pointer %q, got %q (%s)", i, pointer,
+						resp.Validation.Violations[i].Pointer, resp.Validation.Violations[i].Message)
+				}
+			}
+		})
+	}
+}
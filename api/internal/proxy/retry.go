@@ -0,0 +1,232 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultRetryBackoff is the delay before the first retry when a policy sets no backoff
+	defaultRetryBackoff = 200 * time.Millisecond
+
+	// defaultMaxRetryBackoff caps the delay between retries when a policy sets no maxBackoff
+	defaultMaxRetryBackoff = 5 * time.Second
+)
+
+// defaultRetryStatuses are retried when a policy lists no status codes
+var defaultRetryStatuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// Attempt reports one call to the backend when the service has a retry policy
+type Attempt struct {
+	StatusCode int     `json:"statusCode,omitempty"` // Zero when no response arrived
+	Error      string  `json:"error,omitempty"`
+	Duration   float64 `json:"duration"` // Milliseconds
+}
+
+// retryPolicy is a service's storage.RetryPolicy with defaults filled in
+type retryPolicy struct {
+	maxAttempts   int
+	backoff       time.Duration
+	maxBackoff    time.Duration
+	statuses      []int
+	networkErrors bool
+}
+
+// exchange is one completed call to the backend
+type exchange struct {
+	resp      *http.Response // Body already read and closed, unless streamed
+	body      []byte
+	truncated bool // body stops at the size limit
+	timings   *Timings
+}
+
+// discard releases an exchange that will not be used, e.g. before a retry
+func (ex *exchange) discard() {
+	ex.resp.Body.Close()
+}
+
+// retryPolicy returns the policy for a request, or nil when the service has none
+// Non-idempotent methods get a single attempt unless the policy allows them.
+func (c *Client) retryPolicy(req *Request) *retryPolicy {
+	config, err := c.store.GetConfig(req.Service)
+	if err != nil || config == nil || config.Retry == nil {
+		return nil
+	}
+	retry := config.Retry
+
+	policy := &retryPolicy{
+		maxAttempts:   max(retry.MaxAttempts, 1),
+		backoff:       defaultRetryBackoff,
+		maxBackoff:    defaultMaxRetryBackoff,
+		statuses:      retry.RetryOn,
+		networkErrors: retry.NetworkErrors,
+	}
+	initial, limit := retry.Backoffs()
+	if initial > 0 {
+		policy.backoff = initial
+	}
+	if limit > 0 {
+		policy.maxBackoff = limit
+	}
+	if len(policy.statuses) == 0 {
+		policy.statuses = defaultRetryStatuses
+	}
+	if !isIdempotent(req.Method) && !retry.AllowNonIdempotent {
+		policy.maxAttempts = 1
+	}
+	return policy
+}
+
+// retryStatus reports whether a response status is worth another attempt
+func (p *retryPolicy) retryStatus(status int) bool {
+	for _, s := range p.statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// retryError reports whether a failed attempt is worth another one
+// Once ctx is done, because the caller left or the response timeout passed, nothing is retried.
+func (p *retryPolicy) retryError(ctx context.Context, err error) bool {
+	if !p.networkErrors || ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, ErrUpstreamRefused) || errors.Is(err, ErrUpstreamUnreachable) ||
+		errors.Is(err, ErrUpstreamDNS) || errors.Is(err, ErrUpstreamTimeout)
+}
+
+// delay returns how long to wait before the next attempt: exponential backoff, or the
+// backend's Retry-After in seconds, capped at maxBackoff either way
+func (p *retryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, p.maxBackoff)
+		}
+	}
+
+	d := p.backoff
+	for i := 1; i < attempt && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.maxBackoff)
+}
+
+// exchangeWithRetries sends httpReq, retrying as policy allows, and returns the last exchange
+// A nil policy sends it once. Attempts are recorded only when there is a policy.
+func exchangeWithRetries(ctx context.Context, client *http.Client, httpReq *http.Request, policy *retryPolicy, maxBody int64) (*exchange, []Attempt, error) {
+	if policy == nil {
+		ex, err := roundTrip(ctx, client, httpReq, maxBody)
+		return ex, nil, err
+	}
+
+	var attempts []Attempt
+	for attempt := 1; ; attempt++ {
+		attemptReq := httpReq
+		if attempt > 1 {
+			var err error
+			if attemptReq, err = rewind(ctx, httpReq); err != nil {
+				return nil, attempts, err
+			}
+		}
+
+		start := time.Now()
+		ex, err := roundTrip(ctx, client, attemptReq, maxBody)
+		if err != nil {
+			attempts = append(attempts, Attempt{Error: err.Error(), Duration: milliseconds(time.Since(start))})
+			if attempt >= policy.maxAttempts || !policy.retryError(ctx, err) {
+				return nil, attempts, err
+			}
+			if err := sleep(ctx, policy.delay(attempt, nil)); err != nil {
+				return nil, attempts, classifyTransportError(ctx, err)
+			}
+			continue
+		}
+
+		attempts = append(attempts, Attempt{StatusCode: ex.resp.StatusCode, Duration: ex.timings.Total})
+		if attempt >= policy.maxAttempts || !policy.retryStatus(ex.resp.StatusCode) {
+			return ex, attempts, nil
+		}
+		if err := sleep(ctx, policy.delay(attempt, ex.resp)); err != nil {
+			// Out of time to retry, so the last answer stands
+			return ex, attempts, nil
+		}
+		ex.discard()
+	}
+}
+
+// roundTrip sends one request and reads up to maxBody bytes of the response body, tracing its phases
+// Anything past maxBody is dropped and the exchange marked truncated. A maxBody of 0 leaves
+// the body unread for the caller to stream and close; timings then end at the headers.
+func roundTrip(ctx context.Context, client *http.Client, httpReq *http.Request, maxBody int64) (*exchange, error) {
+	trace := newTimingTrace()
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), trace.clientTrace()))
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, classifyTransportError(ctx, err)
+	}
+	if maxBody == 0 {
+		return &exchange{resp: httpResp, timings: trace.finish()}, nil
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, maxBody+1))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, classifyTransportError(ctx, err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrUpstreamResponse, err)
+	}
+
+	ex := &exchange{resp: httpResp, body: respBody, timings: trace.finish()}
+	if int64(len(respBody)) > maxBody {
+		ex.body, ex.truncated = respBody[:maxBody], true
+	}
+	return ex, nil
+}
+
+// rewind copies a request with a fresh body for another attempt
+func rewind(ctx context.Context, httpReq *http.Request) (*http.Request, error) {
+	next := httpReq.Clone(ctx)
+	if httpReq.Body == nil || httpReq.Body == http.NoBody {
+		return next, nil
+	}
+	if httpReq.GetBody == nil {
+		return nil, fmt.Errorf("%w: request body cannot be replayed for a retry", ErrInvalidRequest)
+	}
+	body, err := httpReq.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to replay request body: %w", ErrInvalidRequest, err)
+	}
+	next.Body = body
+	return next, nil
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isIdempotent reports whether RFC 9110 lets a method be repeated safely
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// flakyBackend fails the first failures calls with 503 and answers 200 afterwards
+func flakyBackend(t *testing.T, failures int32, calls *atomic.Int32, bodies chan<- string) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bodies != nil {
+			body, _ := io.ReadAll(r.Body)
+			bodies <- string(body)
+		}
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func TestClient_Forward_RetriesStatus(t *testing.T) {
+	var calls atomic.Int32
+	backend := flakyBackend(t, 2, &calls, nil)
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL, Retry: &storage.RetryPolicy{MaxAttempts: 3, Backoff: "1ms"}},
+		},
+	}
+
+	resp, err := NewClient(store).Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: "/flaky"})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the final status 200, got %d", resp.StatusCode)
+	}
+	if len(resp.Attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %+v", resp.Attempts)
+	}
+	for i, want := range []int{503, 503, 200} {
+		if resp.Attempts[i].StatusCode != want {
+			t.Errorf("attempt %d: expected status %d, got %d", i+1, want, resp.Attempts[i].StatusCode)
+		}
+		if resp.Attempts[i].Duration <= 0 {
+			t.Errorf("attempt %d: expected a duration, got %v", i+1, resp.Attempts[i].Duration)
+		}
+	}
+}
+
+func TestClient_Forward_RetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	backend := flakyBackend(t, 10, &calls, nil)
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL, Retry: &storage.RetryPolicy{MaxAttempts: 2, Backoff: "1ms"}},
+		},
+	}
+
+	resp, err := NewClient(store).Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: "/down"})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the last status 503, got %d", resp.StatusCode)
+	}
+	if len(resp.Attempts) != 2 || calls.Load() != 2 {
+		t.Errorf("expected 2 attempts, got %+v (%d calls)", resp.Attempts, calls.Load())
+	}
+}
+
+func TestClient_Forward_RetriesNonIdempotent(t *testing.T) {
+	tests := []struct {
+		name      string
+		allow     bool
+		wantCalls int32
+	}{
+		{"not allowed", false, 1},
+		{"allowed", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			bodies := make(chan string, 3)
+			backend := flakyBackend(t, 1, &calls, bodies)
+
+			store := &mockSpecStore{
+				configs: map[string]*storage.ServiceConfig{
+					"test-service": {BaseURL: backend.URL, Retry: &storage.RetryPolicy{
+						MaxAttempts: 3, Backoff: "1ms", AllowNonIdempotent: tt.allow,
+					}},
+				},
+			}
+
+			resp, err := NewClient(store).Forward(context.Background(), &Request{
+				Service: "test-service",
+				Method:  http.MethodPost,
+				Path:    "/items",
+				Body:    []byte(`{"name":"retry"}`),
+			})
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+
+			if calls.Load() != tt.wantCalls || len(resp.Attempts) != int(tt.wantCalls) {
+				t.Fatalf("expected %d calls, got %d (%+v)", tt.wantCalls, calls.Load(), resp.Attempts)
+			}
+			close(bodies)
+			for body := range bodies {
+				if body != `{"name":"retry"}` {
+					t.Errorf("expected every attempt to send the body, got %q", body)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Forward_RetriesNetworkErrors(t *testing.T) {
+	var calls atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// Drop the connection without answering
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name          string
+		networkErrors bool
+		wantErr       error
+	}{
+		{"disabled", false, ErrUpstreamUnreachable},
+		{"enabled", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			store := &mockSpecStore{
+				configs: map[string]*storage.ServiceConfig{
+					"test-service": {BaseURL: backend.URL, Retry: &storage.RetryPolicy{
+						MaxAttempts: 2, Backoff: "1ms", NetworkErrors: tt.networkErrors,
+					}},
+				},
+			}
+
+			resp, err := NewClient(store).Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: "/drop"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			if len(resp.Attempts) != 2 || resp.Attempts[0].Error == "" || resp.Attempts[1].StatusCode != http.StatusNoContent {
+				t.Errorf("expected a failed attempt then a 204, got %+v", resp.Attempts)
+			}
+		})
+	}
+}
+
+func TestClient_Forward_NoRetryPolicy(t *testing.T) {
+	var calls atomic.Int32
+	backend := flakyBackend(t, 1, &calls, nil)
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL},
+		},
+	}
+
+	resp, err := NewClient(store).Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: "/flaky"})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Attempts != nil {
+		t.Errorf("expected a single unreported attempt, got status %d and %+v", resp.StatusCode, resp.Attempts)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := &retryPolicy{backoff: 100 * time.Millisecond, maxBackoff: time.Second}
+
+	for attempt, want := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		5: time.Second,
+	} {
+		if got := policy.delay(attempt, nil); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+
+	for retryAfter, want := range map[string]time.Duration{
+		"0":     0,
+		"3":     time.Second,
+		"later": 100 * time.Millisecond,
+	} {
+		resp := &http.Response{Header: http.Header{"Retry-After": {retryAfter}}}
+		if got := policy.delay(1, resp); got != want {
+			t.Errorf("Retry-After %q: expected %v, got %v", retryAfter, want, got)
+		}
+	}
+}
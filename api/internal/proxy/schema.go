@@ -0,0 +1,354 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSchemaDepth bounds nested schema evaluation so recursive $refs cannot loop forever
+const maxSchemaDepth = 64
+
+// uuidPattern matches the canonical textual form of a UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// schemaValidator checks decoded JSON values against OpenAPI 3.0/3.1 schema objects
+// It covers the keywords request builders commonly rely on: type, nullable, enum, const,
+// string/number/array/object bounds, properties, required, additionalProperties,
+// allOf/anyOf/oneOf/not and a handful of formats. Unknown keywords are ignored.
+type schemaValidator struct {
+	spec       map[string]interface{} // Document the schemas' local $refs point into
+	request    bool                   // Validating a request rather than a response, see skipRequired
+	depth      int
+	violations []Violation
+}
+
+func (v *schemaValidator) addf(pointer, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether value satisfies schema, without recording violations
+func (v *schemaValidator) matches(schema, value interface{}) bool {
+	sub := &schemaValidator{spec: v.spec, request: v.request, depth: v.depth}
+	sub.validate(schema, value, "")
+	return len(sub.violations) == 0
+}
+
+// validate records a violation for every way value fails schema; pointer locates value in the request
+func (v *schemaValidator) validate(schema, value interface{}, pointer string) {
+	s, ok := resolveRef(v.spec, schema).(map[string]interface{})
+	if !ok || v.depth >= maxSchemaDepth {
+		return
+	}
+	v.depth++
+	defer func() { v.depth-- }()
+
+	if types := schemaTypes(s); len(types) > 0 && !matchesType(value, types) {
+		v.addf(pointer, "expected %s, got %s", strings.Join(types, " or "), jsonType(value))
+		return
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok && !containsJSON(enum, value) {
+		// 3.0 nullable schemas accept null even when the enum omits it
+		if !(value == nil && s["nullable"] == true) {
+			v.addf(pointer, "must be one of %s", compactJSON(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		v.addf(pointer, "must be %s", compactJSON(c))
+	}
+
+	switch val := value.(type) {
+	case string:
+		v.validateString(s, val, pointer)
+	case float64:
+		v.validateNumber(s, val, pointer)
+	case []interface{}:
+		v.validateArray(s, val, pointer)
+	case map[string]interface{}:
+		v.validateObject(s, val, pointer)
+	}
+
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validate(sub, value, pointer)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.matches(sub, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.addf(pointer, "does not match any of the anyOf schemas")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		count := 0
+		for _, sub := range oneOf {
+			if v.matches(sub, value) {
+				count++
+			}
+		}
+		if count != 1 {
+			v.addf(pointer, "must match exactly one of the oneOf schemas, matched %d", count)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches(not, value) {
+		v.addf(pointer, "must not match the not schema")
+	}
+}
+
+func (v *schemaValidator) validateString(s map[string]interface{}, val, pointer string) {
+	length := utf8.RuneCountInString(val)
+	if limit, ok := number(s["minLength"]); ok && float64(length) < limit {
+		v.addf(pointer, "must be at least %v characters", limit)
+	}
+	if limit, ok := number(s["maxLength"]); ok && float64(length) > limit {
+		v.addf(pointer, "must be at most %v characters", limit)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		// Patterns Go cannot compile (e.g. lookaheads) are skipped rather than failing every request
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(val) {
+			v.addf(pointer, "must match pattern %s", pattern)
+		}
+	}
+
+	format, _ := s["format"].(string)
+	if !validFormat(format, val) {
+		v.addf(pointer, "must be a valid %s", format)
+	}
+}
+
+func (v *schemaValidator) validateNumber(s map[string]interface{}, val float64, pointer string) {
+	if limit, ok := number(s["minimum"]); ok {
+		// 3.0 uses a boolean exclusiveMinimum, 3.1 a number
+		if s["exclusiveMinimum"] == true && val <= limit {
+			v.addf(pointer, "must be greater than %v", limit)
+		} else if val < limit {
+			v.addf(pointer, "must be at least %v", limit)
+		}
+	}
+	if limit, ok := number(s["exclusiveMinimum"]); ok && val <= limit {
+		v.addf(pointer, "must be greater than %v", limit)
+	}
+	if limit, ok := number(s["maximum"]); ok {
+		if s["exclusiveMaximum"] == true && val >= limit {
+			v.addf(pointer, "must be less than %v", limit)
+		} else if val > limit {
+			v.addf(pointer, "must be at most %v", limit)
+		}
+	}
+	if limit, ok := number(s["exclusiveMaximum"]); ok && val >= limit {
+		v.addf(pointer, "must be less than %v", limit)
+	}
+	if mult, ok := number(s["multipleOf"]); ok && mult > 0 {
+		if q := val / mult; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.addf(pointer, "must be a multiple of %v", mult)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(s map[string]interface{}, val []interface{}, pointer string) {
+	if limit, ok := number(s["minItems"]); ok && float64(len(val)) < limit {
+		v.addf(pointer, "must have at least %v items", limit)
+	}
+	if limit, ok := number(s["maxItems"]); ok && float64(len(val)) > limit {
+		v.addf(pointer, "must have at most %v items", limit)
+	}
+	if s["uniqueItems"] == true {
+		for i := 1; i < len(val); i++ {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(val[i], val[j]) {
+					v.addf(pointer+"/"+strconv.Itoa(i), "duplicates item %d", j)
+				}
+			}
+		}
+	}
+	if items, ok := s["items"]; ok {
+		for i, item := range val {
+			v.validate(items, item, pointer+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s map[string]interface{}, val map[string]interface{}, pointer string) {
+	properties, _ := s["properties"].(map[string]interface{})
+
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, present := val[name]; present {
+				continue
+			}
+			if v.skipRequired(properties[name]) {
+				continue
+			}
+			v.addf(pointer+"/"+escapeToken(name), "missing required property %q", name)
+		}
+	}
+
+	if limit, ok := number(s["minProperties"]); ok && float64(len(val)) < limit {
+		v.addf(pointer, "must have at least %v properties", limit)
+	}
+	if limit, ok := number(s["maxProperties"]); ok && float64(len(val)) > limit {
+		v.addf(pointer, "must have at most %v properties", limit)
+	}
+
+	for _, name := range sortedNames(val) {
+		propPtr := pointer + "/" + escapeToken(name)
+		if prop, ok := properties[name]; ok {
+			v.validate(prop, val[name], propPtr)
+			continue
+		}
+		switch extra := s["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.addf(propPtr, "unknown property %q", name)
+			}
+		case map[string]interface{}:
+			v.validate(extra, val[name], propPtr)
+		}
+	}
+}
+
+// skipRequired reports whether a required property may be absent in this direction:
+// readOnly properties are not sent in requests and writeOnly ones are not returned in responses
+func (v *schemaValidator) skipRequired(schema interface{}) bool {
+	s, _ := resolveRef(v.spec, schema).(map[string]interface{})
+	if v.request {
+		return s["readOnly"] == true
+	}
+	return s["writeOnly"] == true
+}
+
+// schemaTypes lists the types a schema allows; 3.0 nullable adds null
+func schemaTypes(s map[string]interface{}) []string {
+	var types []string
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+	if len(types) > 0 && s["nullable"] == true {
+		types = append(types, "null")
+	}
+	return types
+}
+
+// matchesType reports whether a decoded JSON value is one of the named JSON schema types
+func matchesType(value interface{}, types []string) bool {
+	for _, t := range types {
+		switch val := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && val == math.Trunc(val)) {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of a decoded value for messages
+func jsonType(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// validFormat checks the string formats worth rejecting early; other formats always pass
+func validFormat(format, val string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, val)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, val)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(val)
+	case "email":
+		at := strings.LastIndexByte(val, '@')
+		return at > 0 && at < len(val)-1
+	}
+	return true
+}
+
+// containsJSON reports whether a decoded JSON list holds a value equal to value
+func containsJSON(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// number reads a numeric schema keyword
+func number(raw interface{}) (float64, bool) {
+	n, ok := raw.(float64)
+	return n, ok
+}
+
+// compactJSON renders a decoded value for messages
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// escapeToken escapes a key for use as a JSON pointer token
+func escapeToken(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchemaValidator(t *testing.T) {
+	var spec map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"components": {"schemas": {
+			"Pet": {
+				"type": "object",
+				"required": ["id", "name"],
+				"additionalProperties": false,
+				"properties": {
+					"id": {"type": "integer", "readOnly": true},
+					"name": {"type": "string", "minLength": 1, "maxLength": 10},
+					"tag": {"type": "string", "nullable": true, "enum": ["dog", "cat"]},
+					"age": {"type": "number", "minimum": 0, "exclusiveMaximum": 30},
+					"born": {"type": "string", "format": "date"},
+					"owners": {"type": "array", "maxItems": 2, "uniqueItems": true, "items": {"$ref": "#/components/schemas/Pet"}},
+					"code": {"type": ["string", "integer"], "pattern": "^[A-Z]+$"}
+				}
+			},
+			"Shape": {"oneOf": [
+				{"type": "object", "required": ["radius"]},
+				{"type": "object", "required": ["side"]}
+			]}
+		}}
+	}`), &spec)
+	if err != nil {
+		t.Fatalf("failed to unmarshal spec: %v", err)
+	}
+
+	pet := map[string]interface{}{"$ref": "#/components/schemas/Pet"}
+	shape := map[string]interface{}{"$ref": "#/components/schemas/Shape"}
+
+	tests := []struct {
+		name     string
+		schema   interface{}
+		value    string
+		want     []string // Pointers of expected violations, in order
+		response bool
+	}{
+		{name: "valid request omits readOnly id", schema: pet, value: `{"name":"Rex","tag":null,"age":3,"born":"2020-01-31","code":7}`},
+		{name: "readOnly still required in responses", schema: pet, value: `{"name":"Rex"}`, want: []string{"/id"}, response: true},
+		{name: "type mismatch", schema: pet, value: `[]`, want: []string{""}},
+		{name: "property problems", schema: pet, value: `{"name":"","tag":"fish","age":30,"born":"31/01/2020","code":"abc","extra":1}`,
+			want: []string{"/age", "/born", "/code", "/extra", "/name", "/tag"}},
+		{name: "nested items", schema: pet, value: `{"name":"Rex","owners":[{"name":"A"},{"name":"A"},{}]}`,
+			want: []string{"/owners", "/owners/1", "/owners/2/name"}},
+		{name: "oneOf match", schema: shape, value: `{"radius":1}`},
+		{name: "oneOf ambiguous", schema: shape, value: `{"radius":1,"side":2}`, want: []string{""}},
+		{name: "integer rejects fraction", schema: map[string]interface{}{"type": "integer"}, value: `1.5`, want: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatalf("failed to unmarshal value: %v", err)
+			}
+
+			v := &schemaValidator{spec: spec, request: !tt.response}
+			v.validate(tt.schema, value, "")
+
+			if len(v.violations) != len(tt.want) {
+				t.Fatalf("expected %d violations, got %+v", len(tt.want), v.violations)
+			}
+			for i, pointer := range tt.want {
+				if v.violations[i].Pointer != pointer {
+					t.Errorf("violation %d: expected pointer %q, got %q (%s)", i, pointer, v.violations[i].Pointer, v.violations[i].Message)
+				}
+			}
+		})
+	}
+}
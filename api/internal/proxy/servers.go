@@ -0,0 +1,199 @@
+package proxy
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// resolveTarget picks the base URL and auth headers for a request
+// x-proxy-config is used unless the caller selects a spec server or the service has no proxy config,
+// in which case the base URL comes from servers[] and auth (if any) still comes from x-proxy-config.
+// spec is the service's decoded spec, nil when it could not be loaded. credentials reports whether
+// the service's auth headers, OAuth2 token and signature may go to the target: a spec server only
+// gets them when it has the configured scheme and host, or a host listed in redirectHosts.
+func (c *Client) resolveTarget(spec *serviceSpec, req *Request) (target *storage.Target, credentials bool, err error) {
+	config, configErr := c.store.GetConfig(req.Service)
+	if configErr == nil && req.Server == nil {
+		target, err := config.Target(req.Environment)
+		if err != nil {
+			return nil, false, invalidRequest("service %s: %w", req.Service, err)
+		}
+		return target, true, nil
+	}
+
+	if spec == nil {
+		return nil, false, fmt.Errorf("%w: %s", ErrServiceNotFound, req.Service)
+	}
+
+	op, _ := findOperation(spec, req.Method, req.Path)
+	servers := specServers(spec.doc, op)
+	if len(servers) == 0 {
+		return nil, false, fmt.Errorf("%w: service %s has no x-proxy-config or servers", ErrServiceNotFound, req.Service)
+	}
+
+	index := 0
+	if req.Server != nil {
+		index = *req.Server
+	}
+	baseURL, err := serverURL(servers, index, req.ServerVariables)
+	if err != nil {
+		return nil, false, invalidRequest("service %s: %w", req.Service, err)
+	}
+
+	target = &storage.Target{BaseURL: baseURL}
+	if configErr == nil {
+		auth, err := config.Target(req.Environment)
+		if err != nil {
+			return nil, false, invalidRequest("service %s: %w", req.Service, err)
+		}
+		target.Environment = auth.Environment
+
+		// Servers may name mocks, sandboxes or plain-http hosts the credentials were not issued for
+		if !sameServiceHost(baseURL, auth) {
+			c.logger.Warn("selected server is not the configured host, sending without credentials",
+				"service", req.Service, "server", index)
+			return target, false, nil
+		}
+		target.AuthHeaders = auth.AuthHeaders
+		target.RedirectHosts = auth.RedirectHosts
+		credentials = true
+	}
+	return target, credentials, nil
+}
+
+// sameServiceHost reports whether a server URL has the scheme of the configured base URL and
+// its host or one of the service's redirectHosts
+func sameServiceHost(serverURL string, configured *storage.Target) bool {
+	server, err := url.Parse(serverURL)
+	if err != nil {
+		return false
+	}
+	base, err := url.Parse(configured.BaseURL)
+	if err != nil || base.Host == "" || !strings.EqualFold(server.Scheme, base.Scheme) {
+		return false
+	}
+	return redirectAllowed(server, base, configured.RedirectHosts)
+}
+
+// specServers returns the servers that apply to a request
+// Operation servers override path item servers, which override the document's
+func specServers(spec map[string]interface{}, op *operation) []interface{} {
+	if op != nil {
+		if servers, ok := op.op["servers"].([]interface{}); ok && len(servers) > 0 {
+			return servers
+		}
+		if servers, ok := op.pathItem["servers"].([]interface{}); ok && len(servers) > 0 {
+			return servers
+		}
+	}
+	servers, _ := spec["servers"].([]interface{})
+	return servers
+}
+
+// serverVariablePattern limits caller-supplied server variable values to host label characters
+// so a value can never add a scheme, userinfo, port, path, query or fragment to the base URL
+var serverVariablePattern = regexp.MustCompile(`^[A-Za-z0-9-]*$`)
+
+// serverURL picks a server by index and substitutes its {variables}
+// Values not supplied fall back to the variable's default; values must be one of its enum when declared.
+// Supplied values may only hold letters, digits and hyphens, and the result must keep the scheme
+// and host the template has with the same values filled in, since the service's credentials go there.
+func serverURL(servers []interface{}, index int, values map[string]string) (string, error) {
+	if index < 0 || index >= len(servers) {
+		return "", fmt.Errorf("server index %d out of range, spec declares %d servers", index, len(servers))
+	}
+
+	server, _ := servers[index].(map[string]interface{})
+	raw, _ := server["url"].(string)
+	if raw == "" {
+		return "", fmt.Errorf("server %d has no url", index)
+	}
+
+	variables, _ := server["variables"].(map[string]interface{})
+	for name := range values {
+		if _, declared := variables[name]; !declared {
+			return "", fmt.Errorf("unknown server variable %q", name)
+		}
+	}
+
+	var substErr error
+	filled := make(map[string]string)
+	resolved := pathParamPattern.ReplaceAllStringFunc(raw, func(m string) string {
+		name := m[1 : len(m)-1]
+		variable, ok := variables[name].(map[string]interface{})
+		if !ok {
+			substErr = fmt.Errorf("server variable %q is not declared", name)
+			return m
+		}
+
+		value, supplied := values[name]
+		if !supplied {
+			value, _ = variable["default"].(string)
+		} else if !serverVariablePattern.MatchString(value) {
+			substErr = fmt.Errorf("server variable %s: %q may only contain letters, digits and hyphens", name, value)
+			return m
+		}
+		filled[name] = value
+
+		if enum, ok := variable["enum"].([]interface{}); ok && len(enum) > 0 && !containsValue(enum, value) {
+			substErr = fmt.Errorf("server variable %s: %q is not one of %v", name, value, enum)
+		}
+		return value
+	})
+	if substErr != nil {
+		return "", substErr
+	}
+
+	// Relative server URLs are relative to the spec's location, which the proxy cannot reach
+	u, err := url.Parse(resolved)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("server URL %q is not an absolute http(s) URL", resolved)
+	}
+
+	if err := checkServerHost(raw, filled, u); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(resolved, "/"), nil
+}
+
+// checkServerHost verifies that substituting variables left the template's scheme and host intact
+// The template is parsed with each {variable} swapped for a marker, then the markers in its
+// scheme and host are replaced by the values and compared with the substituted URL.
+func checkServerHost(raw string, filled map[string]string, resolved *url.URL) error {
+	markers := make(map[string]string)
+	template := pathParamPattern.ReplaceAllStringFunc(raw, func(m string) string {
+		marker := fmt.Sprintf("srvvar%dx", len(markers))
+		markers[marker] = filled[m[1:len(m)-1]]
+		return marker
+	})
+
+	u, err := url.Parse(template)
+	if err != nil {
+		return fmt.Errorf("server URL template %q is not a valid URL: %v", raw, err)
+	}
+	scheme, host := u.Scheme, u.Host
+	for marker, value := range markers {
+		scheme = strings.ReplaceAll(scheme, marker, value)
+		host = strings.ReplaceAll(host, marker, value)
+	}
+
+	if !strings.EqualFold(resolved.Scheme, scheme) || !strings.EqualFold(resolved.Host, host) || resolved.User != nil {
+		return fmt.Errorf("server variables must not change the host of %q", raw)
+	}
+	return nil
+}
+
+// containsValue reports whether a decoded JSON list holds the string value
+func containsValue(list []interface{}, value string) bool {
+	for _, item := range list {
+		if s, ok := item.(string); ok && s == value {
+			return true
+		}
+	}
+	return false
+}
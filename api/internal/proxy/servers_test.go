@@ -0,0 +1,157 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+func TestServerURL(t *testing.T) {
+	var servers []interface{}
+	err := json.Unmarshal([]byte(`[
+		{"url": "https://{region}.api.example.com/{version}/",
+		 "variables": {
+			"region": {"default": "eu", "enum": ["eu", "us"]},
+			"version": {"default": "v1"}
+		 }},
+		{"url": "/relative"},
+		{"url": "https://{missing}.example.com"},
+		{"url": "https://{tenant}.api.example.com",
+		 "variables": {"tenant": {"default": "acme"}}},
+		{"url": "https://{host}",
+		 "variables": {"host": {"default": "example.com/v1"}}}
+	]`), &servers)
+	if err != nil {
+		t.Fatalf("failed to unmarshal servers: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		index   int
+		values  map[string]string
+		want    string
+		wantErr string
+	}{
+		{name: "defaults", want: "https://eu.api.example.com/v1"},
+		{name: "supplied values", values: map[string]string{"region": "us", "version": "v2"}, want: "https://us.api.example.com/v2"},
+		{name: "value outside enum", values: map[string]string{"region": "ap"}, wantErr: "not one of"},
+		{name: "unknown variable", values: map[string]string{"tenant": "x"}, wantErr: "unknown server variable"},
+		{name: "relative url", index: 1, wantErr: "not an absolute"},
+		{name: "undeclared variable", index: 2, wantErr: "not declared"},
+		{name: "free-form variable", index: 3, values: map[string]string{"tenant": "blue-42"}, want: "https://blue-42.api.example.com"},
+		{name: "injected host", index: 3, values: map[string]string{"tenant": "x@127.0.0.1:8080/#"}, wantErr: "may only contain"},
+		{name: "injected port", index: 3, values: map[string]string{"tenant": "x:1"}, wantErr: "may only contain"},
+		{name: "injected path", index: 3, values: map[string]string{"tenant": "evil.example.com/"}, wantErr: "may only contain"},
+		{name: "default changes host", index: 4, wantErr: "must not change the host"},
+		{name: "out of range", index: 5, wantErr: "out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := serverURL(servers, tt.index, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("serverURL() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClient_Forward_SpecServers(t *testing.T) {
+	var gotPath, gotAuth, gotSignature string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotSignature = r.Header.Get("X-Signature")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+
+	spec := json.RawMessage(`{
+		"openapi": "3.0.0",
+		"servers": [
+			{"url": "` + backend.URL + `/{version}", "variables": {"version": {"default": "v1", "enum": ["v1", "v2"]}}},
+			{"url": "http://unreachable.invalid"}
+		],
+		"paths": {
+			"/pets": {"get": {}},
+			"/legacy": {"servers": [{"url": "` + backend.URL + `/old"}], "get": {}}
+		}
+	}`)
+
+	signing := &storage.SigningConfig{Type: "hmac-sha256", Secret: "s"}
+	store := &mockSpecStore{
+		specs: map[string]json.RawMessage{"no-config": spec, "with-config": spec, "other-host": spec, "redirect-host": spec},
+		configs: map[string]*storage.ServiceConfig{
+			"with-config":   {BaseURL: backend.URL + "/v1", AuthHeaders: map[string]string{"Authorization": "Bearer k"}, Signing: signing},
+			"other-host":    {BaseURL: "http://unreachable.invalid", AuthHeaders: map[string]string{"Authorization": "Bearer k"}, Signing: signing},
+			"redirect-host": {BaseURL: "http://unreachable.invalid", AuthHeaders: map[string]string{"Authorization": "Bearer k"}, RedirectHosts: []string{"127.0.0.1"}},
+		},
+	}
+	client := NewClient(store)
+
+	first := 0
+	tests := []struct {
+		name       string
+		req        Request
+		wantPath   string
+		wantAuth   string
+		wantSigned bool
+	}{
+		{name: "no proxy config uses first server", req: Request{Service: "no-config", Path: "/pets"}, wantPath: "/v1/pets"},
+		{name: "server variables", req: Request{Service: "no-config", Path: "/pets", ServerVariables: map[string]string{"version": "v2"}}, wantPath: "/v2/pets"},
+		{name: "path item servers", req: Request{Service: "no-config", Path: "/legacy"}, wantPath: "/old/legacy"},
+		{name: "selected server keeps config auth", req: Request{Service: "with-config", Path: "/pets", Server: &first}, wantPath: "/v1/pets", wantAuth: "Bearer k", wantSigned: true},
+		{name: "selected server on another host gets no auth", req: Request{Service: "other-host", Path: "/pets", Server: &first}, wantPath: "/v1/pets"},
+		{name: "selected server in redirect hosts keeps auth", req: Request{Service: "redirect-host", Path: "/pets", Server: &first}, wantPath: "/v1/pets", wantAuth: "Bearer k"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotAuth, gotSignature = "", "", ""
+			tt.req.Method = http.MethodGet
+
+			resp, err := client.Forward(context.Background(), &tt.req)
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusNoContent {
+				t.Errorf("expected status 204, got %d", resp.StatusCode)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("expected path %s, got %s", tt.wantPath, gotPath)
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("expected Authorization %q, got %q", tt.wantAuth, gotAuth)
+			}
+			if signed := gotSignature != ""; signed != tt.wantSigned {
+				t.Errorf("expected signed %v, got signature %q", tt.wantSigned, gotSignature)
+			}
+		})
+	}
+
+	_, err := client.Forward(context.Background(), &Request{Service: "no-config", Method: http.MethodGet, Path: "/pets", ServerVariables: map[string]string{"version": "v9"}})
+	if err == nil || !strings.Contains(err.Error(), "not one of") {
+		t.Errorf("expected enum error, got %v", err)
+	}
+
+	store.specs["no-servers"] = json.RawMessage(`{"openapi": "3.0.0", "paths": {"/pets": {"get": {}}}}`)
+	_, err = client.Forward(context.Background(), &Request{Service: "no-servers", Method: http.MethodGet, Path: "/pets"})
+	if !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+}
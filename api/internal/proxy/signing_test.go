@@ -0,0 +1,234 @@
+package proxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+func TestSigV4Signer_AWSTestSuite(t *testing.T) {
+	// get-vanilla from the AWS Signature Version 4 test suite
+	signer := &sigV4Signer{
+		accessKeyID:     "AKIDEXAMPLE",
+		secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:          "us-east-1",
+		service:         "service",
+		now:             func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err := signer.Sign(req); err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("Authorization = %q, want %q", got, expected)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %q", got)
+	}
+}
+
+func TestSigV4Signer_SessionTokenAndS3(t *testing.T) {
+	signer := &sigV4Signer{
+		accessKeyID:     "AKIDEXAMPLE",
+		secretAccessKey: "secret",
+		sessionToken:    "session",
+		region:          "eu-west-1",
+		service:         "s3",
+		now:             time.Now,
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/a%20key?b=2&a=1", strings.NewReader("data"))
+	if err := signer.Sign(req); err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte("data"))
+	if got := req.Header.Get("X-Amz-Content-Sha256"); got != hex.EncodeToString(sum[:]) {
+		t.Errorf("X-Amz-Content-Sha256 = %q", got)
+	}
+	if req.Header.Get("X-Amz-Security-Token") != "session" {
+		t.Error("expected the session token header")
+	}
+	if auth := req.Header.Get("Authorization"); !strings.Contains(auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+		t.Errorf("unexpected signed headers in %q", auth)
+	}
+}
+
+func TestSigV4Path(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/a%20b/c%2Fd", nil)
+	if got := sigV4Path(req.URL, false); got != "/a%20b/c%2Fd" {
+		t.Errorf("single encoding = %q", got)
+	}
+	if got := sigV4Path(req.URL, true); got != "/a%2520b/c%252Fd" {
+		t.Errorf("double encoding = %q", got)
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := `{"amount":1}`
+	bodySum := sha256.Sum256([]byte(body))
+
+	tests := []struct {
+		name      string
+		config    storage.SigningConfig
+		header    string
+		canonical string
+		encode    func([]byte) string
+		timestamp bool
+	}{
+		{
+			name:      "defaults",
+			config:    storage.SigningConfig{Secret: "key"},
+			header:    "X-Signature",
+			canonical: "POST\n/orders?id=7\n1700000000\n" + hex.EncodeToString(bodySum[:]),
+			encode:    hex.EncodeToString,
+			timestamp: true,
+		},
+		{
+			name: "custom template and base64",
+			config: storage.SigningConfig{
+				Secret:          "key",
+				Header:          "X-Hub-Signature",
+				CanonicalString: "{method} {host} {query} {header:X-Tenant} {body}",
+				Encoding:        "base64",
+			},
+			header:    "X-Hub-Signature",
+			canonical: "POST api.example.com id=7 acme " + body,
+			encode:    base64.StdEncoding.EncodeToString,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := newHMACSigner(&tt.config)
+			if err != nil {
+				t.Fatalf("newHMACSigner() failed: %v", err)
+			}
+			signer.(*hmacSigner).now = func() time.Time { return now }
+
+			req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/orders?id=7", strings.NewReader(body))
+			req.Header.Set("X-Tenant", "acme")
+			if err := signer.Sign(req); err != nil {
+				t.Fatalf("Sign() failed: %v", err)
+			}
+
+			mac := hmac.New(sha256.New, []byte("key"))
+			mac.Write([]byte(tt.canonical))
+			if got, want := req.Header.Get(tt.header), tt.encode(mac.Sum(nil)); got != want {
+				t.Errorf("%s = %q, want %q", tt.header, got, want)
+			}
+			if got := req.Header.Get("X-Timestamp"); (got != "") != tt.timestamp {
+				t.Errorf("X-Timestamp = %q, expected it set: %v", got, tt.timestamp)
+			}
+		})
+	}
+}
+
+// staticSigner is a custom signer type registered through WithSigner
+type staticSigner struct{ value string }
+
+func (s staticSigner) Sign(req *http.Request) error {
+	req.Header.Set("X-Custom-Signature", s.value)
+	return nil
+}
+
+func TestClient_Forward_Signing(t *testing.T) {
+	var seen http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name    string
+		signing *storage.SigningConfig
+		check   func(t *testing.T, header http.Header)
+		wantErr bool
+	}{
+		{
+			name:    "basic",
+			signing: &storage.SigningConfig{Type: storage.SignerBasic, Username: "user", Password: "pass"},
+			check: func(t *testing.T, header http.Header) {
+				if got := header.Get("Authorization"); got != "Basic dXNlcjpwYXNz" {
+					t.Errorf("Authorization = %q", got)
+				}
+			},
+		},
+		{
+			name:    "aws-sigv4",
+			signing: &storage.SigningConfig{Type: storage.SignerAWSSigV4, AccessKeyID: "AKID", SecretAccessKey: "secret", Region: "us-east-1", Service: "execute-api"},
+			check: func(t *testing.T, header http.Header) {
+				// The caller's header is signed since it was set before signing
+				if got := header.Get("Authorization"); !strings.HasPrefix(got, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(got, "x-request-id") {
+					t.Errorf("Authorization = %q", got)
+				}
+			},
+		},
+		{
+			name:    "custom",
+			signing: &storage.SigningConfig{Type: "static", Options: map[string]string{"value": "v1"}},
+			check: func(t *testing.T, header http.Header) {
+				if got := header.Get("X-Custom-Signature"); got != "v1" {
+					t.Errorf("X-Custom-Signature = %q", got)
+				}
+			},
+		},
+		{
+			name:    "unknown type",
+			signing: &storage.SigningConfig{Type: "nope"},
+			wantErr: true,
+		},
+		{
+			name:    "missing credentials",
+			signing: &storage.SigningConfig{Type: storage.SignerHMAC},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = nil
+			store := &mockSpecStore{
+				configs: map[string]*storage.ServiceConfig{
+					"orders": {BaseURL: backend.URL, Signing: tt.signing},
+				},
+			}
+			client := NewClient(store, WithSigner("static", func(config *storage.SigningConfig) (Signer, error) {
+				return staticSigner{value: config.Options["value"]}, nil
+			}))
+
+			_, err := client.Forward(context.Background(), &Request{
+				Service: "orders",
+				Method:  http.MethodPost,
+				Path:    "/orders",
+				Headers: map[string]string{"X-Request-Id": "42"},
+				Body:    []byte(`{"amount":1}`),
+			})
+			if tt.wantErr {
+				if err == nil || seen != nil {
+					t.Fatalf("expected an error before reaching the backend, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			tt.check(t, seen)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// maxRedirects matches the limit http.Client applies by default
+const maxRedirects = 10
+
+// redirectHostsKey carries a request's extra allowed redirect hosts to checkRedirect
+type redirectHostsKey struct{}
+
+// WithDeniedNetworks blocks connections to addresses in the given ranges, e.g. link-local
+// and cloud metadata endpoints. The check runs at dial time, after DNS resolution, so it
+// also covers redirects and host names that resolve into a denied range.
+func WithDeniedNetworks(prefixes ...netip.Prefix) Option {
+	return func(c *Client) {
+		c.deniedNetworks = append(c.deniedNetworks, prefixes...)
+	}
+}
+
+// joinTarget appends a request path (with optional query) to a base URL
+// The path must stay on the base URL's scheme and host and may not climb out of its path,
+// so values like @evil.host/, //evil.host/ or /../admin are rejected.
+func joinTarget(baseURL, path string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Host == "" {
+		return "", errors.New("invalid base URL")
+	}
+	if path != "" && !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "?") {
+		return "", invalidRequest("path %q must start with /", path)
+	}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return "", invalidRequest("invalid path %q: %w", path, err)
+	}
+	if ref.Scheme != "" || ref.Host != "" || ref.User != nil {
+		return "", invalidRequest("path %q must not change the target host", path)
+	}
+	for _, segment := range strings.Split(ref.Path, "/") {
+		if segment == ".." {
+			return "", invalidRequest("path %q must not contain .. segments", path)
+		}
+	}
+
+	joined := strings.TrimRight(baseURL, "/") + path
+	target, err := url.Parse(joined)
+	if err != nil {
+		return "", invalidRequest("invalid path %q: %w", path, err)
+	}
+	if target.Scheme != base.Scheme || target.Host != base.Host || target.User.String() != base.User.String() {
+		return "", invalidRequest("path %q must not change the target host", path)
+	}
+	return joined, nil
+}
+
+// checkRedirect follows redirects only to the original host or the service's redirectHosts
+// A blocked redirect is not an error: the 3xx response is returned to the caller as is.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	allowed, _ := req.Context().Value(redirectHostsKey{}).([]string)
+	if !redirectAllowed(req.URL, via[0].URL, allowed) {
+		return http.ErrUseLastResponse
+	}
+	return nil
+}
+
+// redirectAllowed reports whether a redirect target is the original host or matches an
+// allowed host pattern: an exact host name, *.example.com for subdomains, or * for any host
+func redirectAllowed(target, original *url.URL, allowed []string) bool {
+	if strings.EqualFold(target.Host, original.Host) {
+		return true
+	}
+
+	host := strings.ToLower(target.Hostname())
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		switch {
+		case pattern == "*":
+			return true
+		case strings.HasPrefix(pattern, "*."):
+			if strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+		case host == pattern:
+			return true
+		}
+	}
+	return false
+}
+
+// denyControl returns a dialer Control hook that refuses addresses in denied ranges
+func denyControl(denied []netip.Prefix) func(network, address string, conn syscall.RawConn) error {
+	return func(network, address string, conn syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return err
+		}
+		addr = addr.Unmap()
+		for _, prefix := range denied {
+			if prefix.Contains(addr) {
+				return fmt.Errorf("%w: %s is in denied range %s", ErrTargetDenied, addr, prefix)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,194 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+func TestJoinTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "path and query", base: "https://api.example.com", path: "/pets?limit=1", want: "https://api.example.com/pets?limit=1"},
+		{name: "base path", base: "https://api.example.com/v1", path: "/pets", want: "https://api.example.com/v1/pets"},
+		{name: "trailing slash", base: "https://api.example.com/v1/", path: "/pets", want: "https://api.example.com/v1/pets"},
+		{name: "empty path", base: "https://api.example.com", path: "", want: "https://api.example.com"},
+		{name: "escaped slash kept", base: "https://api.example.com", path: "/files/a%2Fb", want: "https://api.example.com/files/a%2Fb"},
+		{name: "userinfo trick", base: "https://api.example.com", path: "@evil.example/", wantErr: true},
+		{name: "scheme-relative", base: "https://api.example.com", path: "//evil.example/x", wantErr: true},
+		{name: "absolute URL", base: "https://api.example.com", path: "http://evil.example/", wantErr: true},
+		{name: "relative path", base: "https://api.example.com", path: "pets", wantErr: true},
+		{name: "dot segments", base: "https://api.example.com/v1", path: "/../admin", wantErr: true},
+		{name: "encoded dot segments", base: "https://api.example.com/v1", path: "/%2e%2e/admin", wantErr: true},
+		{name: "port change", base: "https://api.example.com", path: ":8443/x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinTarget(tt.base, tt.path)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRequest) {
+					t.Fatalf("expected ErrInvalidRequest, got %q (%v)", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("joinTarget() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRedirectAllowed(t *testing.T) {
+	original, _ := url.Parse("https://api.example.com/login")
+
+	tests := []struct {
+		target  string
+		allowed []string
+		want    bool
+	}{
+		{"https://api.example.com/home", nil, true},
+		{"https://API.example.com/home", nil, true},
+		{"https://api.example.com:8443/home", nil, false},
+		{"https://evil.example/", nil, false},
+		{"https://auth.example.com/", []string{"auth.example.com"}, true},
+		{"https://eu.auth.example.com/", []string{"*.example.com"}, true},
+		{"https://example.com.evil/", []string{"*.example.com"}, false},
+		{"https://anything.test/", []string{"*"}, true},
+	}
+
+	for _, tt := range tests {
+		target, _ := url.Parse(tt.target)
+		if got := redirectAllowed(target, original, tt.allowed); got != tt.want {
+			t.Errorf("redirect to %s with %v: expected %v, got %v", tt.target, tt.allowed, tt.want, got)
+		}
+	}
+}
+
+func TestClient_Forward_Redirects(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("other host"))
+	}))
+	defer other.Close()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/here", http.StatusFound)
+		case "/away":
+			// localhost is a different host from 127.0.0.1 as far as redirects are concerned
+			http.Redirect(w, r, strings.Replace(other.URL, "127.0.0.1", "localhost", 1)+"/", http.StatusFound)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("same host"))
+		}
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name          string
+		path          string
+		redirectHosts []string
+		wantStatus    int
+		wantBody      string
+	}{
+		{name: "same host followed", path: "/moved", wantStatus: http.StatusOK, wantBody: `"same host"`},
+		{name: "other host returned", path: "/away", wantStatus: http.StatusFound},
+		{name: "allowed host followed", path: "/away", redirectHosts: []string{"localhost"}, wantStatus: http.StatusOK, wantBody: `"other host"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockSpecStore{
+				configs: map[string]*storage.ServiceConfig{
+					"test-service": {BaseURL: backend.URL, RedirectHosts: tt.redirectHosts},
+				},
+			}
+
+			resp, err := NewClient(store).Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: tt.path})
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantBody != "" && string(resp.Body) != tt.wantBody {
+				t.Errorf("expected body %s, got %s", tt.wantBody, resp.Body)
+			}
+		})
+	}
+}
+
+func TestClient_Forward_DeniedNetworks(t *testing.T) {
+	var calls atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL},
+		},
+	}
+
+	client := NewClient(store, WithDeniedNetworks(netip.MustParsePrefix("127.0.0.0/8")))
+	_, err := client.Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: "/"})
+	if !errors.Is(err, ErrTargetDenied) {
+		t.Fatalf("expected ErrTargetDenied, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Error("expected the denied address not to be reached")
+	}
+
+	allowed := NewClient(store, WithDeniedNetworks(netip.MustParsePrefix("169.254.0.0/16")))
+	if _, err := allowed.Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: "/"}); err != nil {
+		t.Fatalf("expected other ranges to be reachable, got %v", err)
+	}
+}
+
+func TestClient_Forward_DeniedNetworksIgnoreEnvironmentProxy(t *testing.T) {
+	var proxied atomic.Int32
+	envProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+	}))
+	defer envProxy.Close()
+	t.Setenv("HTTP_PROXY", envProxy.URL)
+	t.Setenv("http_proxy", envProxy.URL)
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"metadata": {BaseURL: "http://169.254.169.254/latest"},
+		},
+	}
+	client := NewClient(store, WithDeniedNetworks(netip.MustParsePrefix("169.254.0.0/16")))
+
+	if transport, ok := client.httpClient.Transport.(*http.Transport); !ok || transport.Proxy != nil {
+		t.Fatal("expected the transport to ignore HTTP_PROXY")
+	}
+
+	_, err := client.Forward(context.Background(), &Request{Service: "metadata", Method: http.MethodGet, Path: "/meta-data"})
+	if !errors.Is(err, ErrTargetDenied) {
+		t.Fatalf("expected ErrTargetDenied, got %v", err)
+	}
+	if proxied.Load() != 0 {
+		t.Error("expected the request not to go through the environment proxy")
+	}
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodySize bounds how much of an upstream body Forward buffers unless WithMaxBodySize says otherwise
+const DefaultMaxBodySize = 10 << 20
+
+// DefaultMaxRequestBodySize bounds a pass-through request body unless WithMaxRequestBodySize says otherwise
+const DefaultMaxRequestBodySize = 10 << 20
+
+// hopHeaders describe a single connection and are not passed on by Stream
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// WithMaxBodySize caps how many bytes of an upstream body Forward reads into its envelope
+// Longer bodies are cut off and flagged as truncated; use Stream to pass them on whole.
+func WithMaxBodySize(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxBodySize = n
+		}
+	}
+}
+
+// WithMaxRequestBodySize caps how many bytes of a pass-through request body are accepted
+// The body is buffered so it can be signed and retried; callers enforce it with MaxRequestBodySize.
+func WithMaxRequestBodySize(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxRequestBodySize = n
+		}
+	}
+}
+
+// MaxRequestBodySize returns the most bytes of a pass-through request body the client accepts
+func (c *Client) MaxRequestBodySize() int64 {
+	return c.maxRequestBodySize
+}
+
+// StreamResponse is an upstream response whose body is read as it arrives
+// Body must be closed; closing it also releases the upstream connection.
+type StreamResponse struct {
+	StatusCode int
+	Header     http.Header // Upstream headers without hop-by-hop ones
+	Body       io.ReadCloser
+	Attempts   []Attempt // Calls made under the service's retry policy, nil without one
+}
+
+// Stream sends the request like Forward but hands back the upstream body unread
+// instead of buffering it into an envelope, for large downloads and event streams.
+// The response timeout only bounds the wait for headers; after that the body may
+// flow for as long as ctx allows. Errors are the same as Forward's.
+func (c *Client) Stream(ctx context.Context, req *Request) (*StreamResponse, error) {
+	call, err := c.prepare(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ex, attempts, err := c.send(call, 0)
+	call.stopHeaderTimer()
+	if err != nil {
+		call.cancel(nil)
+		return nil, err
+	}
+
+	header := ex.resp.Header.Clone()
+	removeHopHeaders(header)
+
+	return &StreamResponse{
+		StatusCode: ex.resp.StatusCode,
+		Header:     header,
+		Body:       &streamBody{ReadCloser: ex.resp.Body, cancel: call.cancel},
+		Attempts:   attempts,
+	}, nil
+}
+
+// streamBody releases the call's context along with the upstream body
+type streamBody struct {
+	io.ReadCloser
+	cancel context.CancelCauseFunc
+}
+
+func (b *streamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel(nil)
+	return err
+}
+
+// removeHopHeaders drops hop-by-hop headers, including any the Connection header names
+func removeHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
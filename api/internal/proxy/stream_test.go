@@ -0,0 +1,165 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+func TestClient_Forward_TruncatesLargeBody(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{"files": {BaseURL: backend.URL}},
+	}
+
+	tests := []struct {
+		name      string
+		limit     int64
+		wantBody  string
+		truncated bool
+	}{
+		{name: "under the limit", limit: 100, wantBody: `"` + strings.Repeat("a", 100) + `"`},
+		{name: "over the limit", limit: 10, wantBody: `"` + strings.Repeat("a", 10) + `"`, truncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := NewClient(store, WithMaxBodySize(tt.limit)).Forward(context.Background(), &Request{
+				Service: "files",
+				Method:  http.MethodGet,
+				Path:    "/big",
+			})
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			if string(resp.Body) != tt.wantBody {
+				t.Errorf("Body = %s, want %s", resp.Body, tt.wantBody)
+			}
+			if resp.Truncated != tt.truncated {
+				t.Errorf("Truncated = %v, want %v", resp.Truncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestClient_Stream(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Connection", "keep-alive, X-Hop")
+		w.Header().Set("X-Hop", "1")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+
+		// The second event only follows once the first has been read through the proxy
+		<-release
+		fmt.Fprint(w, "data: second\n\n")
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{"events": {BaseURL: backend.URL}},
+	}
+
+	resp, err := NewClient(store).Stream(context.Background(), &Request{Service: "events", Method: http.MethodGet, Path: "/events"})
+	if err != nil {
+		t.Fatalf("Stream() failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Errorf("unexpected status %d and headers %v", resp.StatusCode, resp.Header)
+	}
+	if resp.Header.Get("Connection") != "" || resp.Header.Get("X-Hop") != "" {
+		t.Errorf("expected hop-by-hop headers to be removed, got %v", resp.Header)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if line, err := reader.ReadString('\n'); err != nil || line != "data: first\n" {
+		t.Fatalf("expected the first event before the stream ends, got %q (%v)", line, err)
+	}
+	close(release)
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading the stream failed: %v", err)
+	}
+	if string(rest) != "\ndata: second\n\n" {
+		t.Errorf("unexpected rest of stream %q", rest)
+	}
+}
+
+func TestClient_Stream_TimeoutOnlyCoversHeaders(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-headers" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("done"))
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{"slow": {BaseURL: backend.URL}},
+	}
+	client := NewClient(store)
+
+	// A body that takes longer than the timeout still streams to the end
+	resp, err := client.Stream(context.Background(), &Request{Service: "slow", Method: http.MethodGet, Path: "/slow-body", Timeout: "100ms"})
+	if err != nil {
+		t.Fatalf("Stream() failed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || string(body) != "done" {
+		t.Errorf("expected the whole body, got %q (%v)", body, err)
+	}
+
+	// Headers that take longer than the timeout fail as a timeout
+	_, err = client.Stream(context.Background(), &Request{Service: "slow", Method: http.MethodGet, Path: "/slow-headers", Timeout: "100ms"})
+	if !errors.Is(err, ErrUpstreamTimeout) {
+		t.Errorf("expected ErrUpstreamTimeout, got %v", err)
+	}
+}
+
+func TestClient_Stream_Canceled(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{"events": {BaseURL: backend.URL}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := NewClient(store).Stream(ctx, &Request{Service: "events", Method: http.MethodGet, Path: "/events"})
+	if err != nil {
+		t.Fatalf("Stream() failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	cancel()
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Error("expected reading the body to fail once the caller cancels")
+	}
+}
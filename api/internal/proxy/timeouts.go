@@ -0,0 +1,53 @@
+package proxy
+
+import "time"
+
+const (
+	// DefaultConnectTimeout bounds dialing a backend when its x-proxy-config sets no connectTimeout
+	DefaultConnectTimeout = 10 * time.Second
+
+	// DefaultResponseTimeout bounds a whole exchange, from dialing to reading the body,
+	// when neither the request nor x-proxy-config sets one
+	DefaultResponseTimeout = 30 * time.Second
+
+	// DefaultMaxTimeout caps service and per-request timeouts unless WithMaxTimeout says otherwise
+	DefaultMaxTimeout = 2 * time.Minute
+)
+
+// connectTimeoutKey carries a request's connect timeout to the shared transport's dialer
+type connectTimeoutKey struct{}
+
+// WithMaxTimeout caps the connect and response timeouts services and requests may ask for
+func WithMaxTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.maxTimeout = d
+		}
+	}
+}
+
+// timeouts picks the connect and response timeouts for a request
+// The request's Timeout overrides the service's responseTimeout; both are capped at the client's maximum.
+func (c *Client) timeouts(req *Request) (connect, response time.Duration, err error) {
+	connect, response = DefaultConnectTimeout, DefaultResponseTimeout
+
+	if config, err := c.store.GetConfig(req.Service); err == nil && config != nil {
+		serviceConnect, serviceResponse := config.Timeouts()
+		if serviceConnect > 0 {
+			connect = serviceConnect
+		}
+		if serviceResponse > 0 {
+			response = serviceResponse
+		}
+	}
+
+	if req.Timeout != "" {
+		override, err := time.ParseDuration(req.Timeout)
+		if err != nil || override <= 0 {
+			return 0, 0, invalidRequest("invalid timeout %q: must be a positive duration, e.g. 10s", req.Timeout)
+		}
+		response = override
+	}
+
+	return min(connect, c.maxTimeout), min(response, c.maxTimeout), nil
+}
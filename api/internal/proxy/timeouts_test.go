@@ -0,0 +1,161 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+func TestClient_Timeouts(t *testing.T) {
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"configured": {BaseURL: "http://api.test", ConnectTimeout: "2s", ResponseTimeout: "45s"},
+			"plain":      {BaseURL: "http://api.test"},
+			"slow":       {BaseURL: "http://api.test", ResponseTimeout: "10m"},
+		},
+	}
+	client := NewClient(store)
+
+	tests := []struct {
+		name         string
+		req          *Request
+		wantConnect  time.Duration
+		wantResponse time.Duration
+		wantErr      error
+	}{
+		{
+			name:         "defaults",
+			req:          &Request{Service: "plain"},
+			wantConnect:  DefaultConnectTimeout,
+			wantResponse: DefaultResponseTimeout,
+		},
+		{
+			name:         "service config",
+			req:          &Request{Service: "configured"},
+			wantConnect:  2 * time.Second,
+			wantResponse: 45 * time.Second,
+		},
+		{
+			name:         "request override",
+			req:          &Request{Service: "configured", Timeout: "5s"},
+			wantConnect:  2 * time.Second,
+			wantResponse: 5 * time.Second,
+		},
+		{
+			name:         "service capped at maximum",
+			req:          &Request{Service: "slow"},
+			wantConnect:  DefaultConnectTimeout,
+			wantResponse: DefaultMaxTimeout,
+		},
+		{
+			name:         "request capped at maximum",
+			req:          &Request{Service: "plain", Timeout: "1h"},
+			wantConnect:  DefaultConnectTimeout,
+			wantResponse: DefaultMaxTimeout,
+		},
+		{
+			name:    "invalid override",
+			req:     &Request{Service: "plain", Timeout: "soon"},
+			wantErr: ErrInvalidRequest,
+		},
+		{
+			name:    "negative override",
+			req:     &Request{Service: "plain", Timeout: "-5s"},
+			wantErr: ErrInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connect, response, err := client.timeouts(tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("timeouts() failed: %v", err)
+			}
+			if connect != tt.wantConnect || response != tt.wantResponse {
+				t.Errorf("expected %v/%v, got %v/%v", tt.wantConnect, tt.wantResponse, connect, response)
+			}
+		})
+	}
+}
+
+func TestClient_Forward_ResponseTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL, ResponseTimeout: "50ms"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		client *Client
+		req    *Request
+	}{
+		{"service timeout", NewClient(store), &Request{Service: "test-service", Method: http.MethodGet, Path: "/slow"}},
+		{"request timeout", NewClient(store), &Request{Service: "test-service", Method: http.MethodGet, Path: "/slow", Timeout: "20ms"}},
+		{"server maximum", NewClient(store, WithMaxTimeout(20*time.Millisecond)), &Request{Service: "test-service", Method: http.MethodGet, Path: "/slow", Timeout: "1m"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := tt.client.Forward(context.Background(), tt.req)
+			if !errors.Is(err, ErrUpstreamTimeout) {
+				t.Fatalf("expected ErrUpstreamTimeout, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("expected the timeout to cut the request short, took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestClient_Forward_Canceled(t *testing.T) {
+	upstreamDone := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(upstreamDone)
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := NewClient(store).Forward(ctx, &Request{Service: "test-service", Method: http.MethodGet, Path: "/slow"})
+	if !errors.Is(err, ErrCanceled) {
+		t.Fatalf("expected ErrCanceled, got %v", err)
+	}
+
+	select {
+	case <-upstreamDone:
+	case <-time.After(time.Second):
+		t.Error("expected the upstream request to be aborted")
+	}
+}
@@ -0,0 +1,122 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings breaks a proxied request down into phases, in milliseconds
+// DNS, connect and TLS are zero when a pooled connection was reused. When redirects are
+// followed the phases add up over every hop and the connection fields describe the last one.
+type Timings struct {
+	DNSLookup        float64 `json:"dnsLookup"`
+	TCPConnect       float64 `json:"tcpConnect"`
+	TLSHandshake     float64 `json:"tlsHandshake"`
+	TimeToFirstByte  float64 `json:"timeToFirstByte"` // From the request being written until the first response byte
+	Download         float64 `json:"download"`        // From the first response byte until the body was read
+	Total            float64 `json:"total"`
+	RemoteAddr       string  `json:"remoteAddr,omitempty"`
+	ConnectionReused bool    `json:"connectionReused"`
+}
+
+// timingTrace collects httptrace events for one Forward call
+// Callbacks may run on transport goroutines, e.g. parallel dials, so fields are guarded by mu
+type timingTrace struct {
+	mu            sync.Mutex
+	start         time.Time
+	dnsStart      time.Time
+	connectStarts map[string]time.Time
+	tlsStart      time.Time
+	wroteRequest  time.Time
+	firstByte     time.Time
+	timings       Timings
+}
+
+func newTimingTrace() *timingTrace {
+	return &timingTrace{
+		start:         time.Now(),
+		connectStarts: make(map[string]time.Time),
+	}
+}
+
+// clientTrace returns the hooks that feed the trace
+func (t *timingTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.DNSLookup += milliseconds(time.Since(t.dnsStart))
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connectStarts[network+" "+addr] = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// Only the dial that won counts; failed attempts at other addresses overlap it
+			if started, ok := t.connectStarts[network+" "+addr]; ok && err == nil {
+				t.timings.TCPConnect += milliseconds(time.Since(started))
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.TLSHandshake += milliseconds(time.Since(t.tlsStart))
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.ConnectionReused = info.Reused
+			if info.Conn != nil {
+				t.timings.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wroteRequest = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+			// Waiting starts once the request is out, so it never overlaps DNS, connect or TLS
+			if !t.wroteRequest.IsZero() {
+				t.timings.TimeToFirstByte += milliseconds(t.firstByte.Sub(t.wroteRequest))
+			}
+		},
+	}
+}
+
+// finish stops the clock once the response body has been read and returns the breakdown
+func (t *timingTrace) finish() *Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	end := time.Now()
+	timings := t.timings
+	timings.Total = milliseconds(end.Sub(t.start))
+	if !t.firstByte.IsZero() {
+		timings.Download = milliseconds(end.Sub(t.firstByte))
+	}
+	return &timings
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+func TestClient_Forward_Timings(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL},
+		},
+	}
+	client := NewClient(store)
+	req := &Request{Service: "test-service", Method: http.MethodGet, Path: "/test"}
+
+	resp, err := client.Forward(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+
+	timings := resp.Timings
+	if timings == nil {
+		t.Fatal("expected timings")
+	}
+	if timings.ConnectionReused {
+		t.Error("expected the first request to open a new connection")
+	}
+	if timings.RemoteAddr != strings.TrimPrefix(backend.URL, "http://") {
+		t.Errorf("expected remote address %s, got %q", backend.URL, timings.RemoteAddr)
+	}
+	if timings.TCPConnect <= 0 {
+		t.Errorf("expected a TCP connect time, got %v", timings.TCPConnect)
+	}
+	if timings.TLSHandshake != 0 {
+		t.Errorf("expected no TLS handshake for plain HTTP, got %v", timings.TLSHandshake)
+	}
+	if timings.TimeToFirstByte < 10 {
+		t.Errorf("expected time to first byte to include the backend's delay, got %v", timings.TimeToFirstByte)
+	}
+	// Phases are converted to milliseconds separately, so allow for float rounding
+	phases := timings.DNSLookup + timings.TCPConnect + timings.TLSHandshake + timings.TimeToFirstByte + timings.Download
+	if timings.Total+0.001 < phases {
+		t.Errorf("expected total %v to cover the phases without overlap, got %+v", timings.Total, timings)
+	}
+
+	resp, err = client.Forward(context.Background(), req)
+	if err != nil {
+		t.Fatalf("second Forward() failed: %v", err)
+	}
+	if !resp.Timings.ConnectionReused {
+		t.Error("expected the second request to reuse the connection")
+	}
+	if resp.Timings.TCPConnect != 0 || resp.Timings.DNSLookup != 0 {
+		t.Errorf("expected no connect or DNS time on a reused connection, got %+v", resp.Timings)
+	}
+}
+
+func TestClient_Forward_TimingsTLS(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"test-service": {BaseURL: backend.URL},
+		},
+	}
+	client := NewClient(store)
+	client.httpClient = backend.Client()
+
+	resp, err := client.Forward(context.Background(), &Request{Service: "test-service", Method: http.MethodGet, Path: "/test"})
+	if err != nil {
+		t.Fatalf("Forward() failed: %v", err)
+	}
+	if resp.Timings.TLSHandshake <= 0 {
+		t.Errorf("expected a TLS handshake time, got %v", resp.Timings.TLSHandshake)
+	}
+}
@@ -0,0 +1,196 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// writePEM writes a single PEM block to dir/name and returns its path
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// clientCertificate creates a self-signed client certificate and returns it with its PEM files
+func clientCertificate(t *testing.T, dir string) (cert *x509.Certificate, certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "playground-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	return cert, writePEM(t, dir, "client.pem", "CERTIFICATE", der), writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+// mtlsBackend starts a TLS server that requires clientCert and reports the peer's common name
+func mtlsBackend(t *testing.T, clientCert *x509.Certificate) *httptest.Server {
+	t.Helper()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	backend.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func TestClient_Forward_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := clientCertificate(t, dir)
+	backend := mtlsBackend(t, clientCert)
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", backend.Certificate().Raw)
+
+	tests := []struct {
+		name    string
+		tls     *storage.TLSConfig
+		wantErr bool
+	}{
+		{name: "client certificate and CA", tls: &storage.TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}},
+		{name: "missing client certificate", tls: &storage.TLSConfig{CAFile: caFile}, wantErr: true},
+		{name: "untrusted server", tls: &storage.TLSConfig{CertFile: certFile, KeyFile: keyFile}, wantErr: true},
+		{name: "insecure skip verify", tls: &storage.TLSConfig{CertFile: certFile, KeyFile: keyFile, InsecureSkipVerify: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockSpecStore{
+				configs: map[string]*storage.ServiceConfig{
+					"internal": {BaseURL: backend.URL, TLS: tt.tls},
+				},
+			}
+			var logs bytes.Buffer
+			client := NewClient(store, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+			resp, err := client.Forward(context.Background(), &Request{Service: "internal", Method: http.MethodGet, Path: "/whoami"})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected the TLS handshake to fail, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Forward() failed: %v", err)
+			}
+			if string(resp.Body) != `"playground-client"` {
+				t.Errorf("expected the backend to see the client certificate, got %s", resp.Body)
+			}
+
+			warned := strings.Contains(logs.String(), "TLS certificate verification disabled")
+			if warned != tt.tls.InsecureSkipVerify {
+				t.Errorf("expected insecure warning logged to be %v, got logs %q", tt.tls.InsecureSkipVerify, logs.String())
+			}
+		})
+	}
+}
+
+func TestClient_Forward_TLSMinVersion(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	backend.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	backend.StartTLS()
+	defer backend.Close()
+
+	caFile := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", backend.Certificate().Raw)
+
+	for version, wantErr := range map[string]bool{"1.2": false, "1.3": true} {
+		store := &mockSpecStore{
+			configs: map[string]*storage.ServiceConfig{
+				"legacy": {BaseURL: backend.URL, TLS: &storage.TLSConfig{CAFile: caFile, MinVersion: version}},
+			},
+		}
+		_, err := NewClient(store).Forward(context.Background(), &Request{Service: "legacy", Method: http.MethodGet, Path: "/"})
+		if (err != nil) != wantErr {
+			t.Errorf("minVersion %s: expected error %v, got %v", version, wantErr, err)
+		}
+	}
+}
+
+func TestClient_HTTPClientForCache(t *testing.T) {
+	backend := httptest.NewTLSServer(http.NotFoundHandler())
+	defer backend.Close()
+	caFile := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", backend.Certificate().Raw)
+
+	config := &storage.ServiceConfig{BaseURL: "https://internal.test", TLS: &storage.TLSConfig{CAFile: caFile}}
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"internal": config,
+			"public":   {BaseURL: "https://public.test"},
+		},
+	}
+	client := NewClient(store)
+
+	first, err := client.httpClientFor("internal")
+	if err != nil {
+		t.Fatalf("httpClientFor() failed: %v", err)
+	}
+	if first == client.httpClient {
+		t.Error("expected a dedicated client for a service with TLS settings")
+	}
+	if again, _ := client.httpClientFor("internal"); again != first {
+		t.Error("expected the dedicated client to be cached")
+	}
+
+	// A reload replaces the config, which rebuilds the transport
+	store.configs["internal"] = &storage.ServiceConfig{BaseURL: config.BaseURL, TLS: &storage.TLSConfig{CAFile: caFile}}
+	if rebuilt, _ := client.httpClientFor("internal"); rebuilt == first {
+		t.Error("expected a new client after the config changed")
+	}
+
+	if shared, _ := client.httpClientFor("public"); shared != client.httpClient {
+		t.Error("expected services without TLS settings to share the default client")
+	}
+
+	store.configs["internal"] = &storage.ServiceConfig{BaseURL: config.BaseURL, TLS: &storage.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}}
+	if _, err := client.httpClientFor("internal"); err == nil {
+		t.Error("expected an error for a missing CA bundle")
+	}
+}
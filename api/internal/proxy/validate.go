@@ -0,0 +1,257 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Violation is a single way a proxied request fails to match its spec operation
+// Pointer locates the offending value: /path/{name}, /query/{name}, /header/{name} or /body/...
+type Violation struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned instead of forwarding when a request fails validation
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 0 {
+		return "request failed validation"
+	}
+	first := e.Violations[0]
+	return fmt.Sprintf("request failed validation: %s: %s (%d violations)", first.Pointer, first.Message, len(e.Violations))
+}
+
+// Unwrap lets callers match ValidationError with errors.Is(err, ErrInvalidRequest)
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// validateRequest checks a request against its spec operation's parameters and JSON body schema
+// injected holds the headers the proxy adds itself (auth), which satisfy required header parameters
+func (c *Client) validateRequest(loaded *serviceSpec, req *Request, injected map[string]string, mode, mediaType string) error {
+	if loaded == nil {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, req.Service)
+	}
+	spec := loaded.doc
+
+	path, rawQuery, _ := strings.Cut(req.Path, "?")
+	op, ok := findOperation(loaded, req.Method, path)
+	if !ok {
+		return &ValidationError{Violations: []Violation{{
+			Pointer: "",
+			Message: fmt.Sprintf("no operation in the spec matches %s %s", req.Method, path),
+		}}}
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return &ValidationError{Violations: []Violation{{Pointer: "/query", Message: fmt.Sprintf("invalid query string: %v", err)}}}
+	}
+
+	headers := make(http.Header)
+	for name, value := range injected {
+		headers.Set(name, value)
+	}
+	for name, value := range req.Headers {
+		headers.Set(name, value)
+	}
+
+	v := &schemaValidator{spec: spec, request: true}
+
+	for _, param := range operationParameters(spec, op) {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+
+		var values []string
+		switch in {
+		case "path":
+			if value, ok := op.pathParams[name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[name]
+		case "header":
+			values = headers.Values(name)
+		default:
+			continue // Cookie parameters are not checked
+		}
+
+		pointer := "/" + in + "/" + escapeToken(name)
+		if len(values) == 0 {
+			if param["required"] == true || in == "path" {
+				v.addf(pointer, "missing required %s parameter %q", in, name)
+			}
+			continue
+		}
+
+		schema, ok := param["schema"]
+		if !ok {
+			continue // content-based parameters are not checked
+		}
+		v.validate(schema, coerceParameter(spec, schema, param, values), pointer)
+	}
+
+	c.validateBody(v, spec, op, req, mode, mediaType)
+
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+// validateBody checks the presence of a required body and validates JSON bodies against their schema
+func (c *Client) validateBody(v *schemaValidator, spec map[string]interface{}, op *operation, req *Request, mode, mediaType string) {
+	body, _ := resolveRef(spec, op.op["requestBody"]).(map[string]interface{})
+	if body == nil {
+		return
+	}
+
+	empty := len(req.Body) == 0 || string(req.Body) == "null"
+	if empty && len(req.Parts) == 0 {
+		if body["required"] == true {
+			v.addf("/body", "request body is required")
+		}
+		return
+	}
+
+	// Only JSON bodies have a structure to check; form and binary payloads pass through
+	if mode != BodyModeJSON || empty {
+		return
+	}
+
+	content, _ := body["content"].(map[string]interface{})
+	media, ok := content[mediaType].(map[string]interface{})
+	if !ok || !isJSONMediaType(mediaType) {
+		media = nil
+		for _, candidate := range requestBodyMediaTypes(spec, op) {
+			if isJSONMediaType(candidate) {
+				media, _ = content[candidate].(map[string]interface{})
+				break
+			}
+		}
+	}
+	schema, ok := media["schema"]
+	if !ok {
+		return
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(req.Body, &value); err != nil {
+		v.addf("/body", "invalid JSON: %v", err)
+		return
+	}
+	v.validate(schema, value, "/body")
+}
+
+// operationParameters merges path item and operation parameters; the operation wins on name and location
+func operationParameters(spec map[string]interface{}, op *operation) []map[string]interface{} {
+	var params []map[string]interface{}
+	seen := make(map[string]bool)
+
+	for _, source := range []interface{}{op.op["parameters"], op.pathItem["parameters"]} {
+		list, _ := source.([]interface{})
+		for _, item := range list {
+			param, ok := resolveRef(spec, item).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			key := in + ":" + name
+			if in == "header" {
+				key = in + ":" + http.CanonicalHeaderKey(name)
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// coerceParameter turns raw parameter strings into the JSON value their schema describes
+// Values that do not parse are left as strings so the type check reports them
+func coerceParameter(spec map[string]interface{}, schema interface{}, param map[string]interface{}, values []string) interface{} {
+	s, _ := resolveRef(spec, schema).(map[string]interface{})
+	types := schemaTypes(s)
+
+	if containsString(types, "array") {
+		// Only exploded form parameters repeat; other styles pack items into one value
+		if len(values) == 1 {
+			values = strings.Split(values[0], parameterDelimiter(param))
+		}
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			items[i] = coerceScalar(spec, s["items"], value)
+		}
+		return items
+	}
+
+	return coerceScalar(spec, schema, values[0])
+}
+
+// parameterDelimiter returns the separator used by a parameter's style
+func parameterDelimiter(param map[string]interface{}) string {
+	switch param["style"] {
+	case "spaceDelimited":
+		return " "
+	case "pipeDelimited":
+		return "|"
+	}
+	return ","
+}
+
+// coerceScalar parses a single parameter value as the schema's primitive type
+func coerceScalar(spec map[string]interface{}, schema interface{}, value string) interface{} {
+	s, _ := resolveRef(spec, schema).(map[string]interface{})
+	types := schemaTypes(s)
+
+	if containsString(types, "integer") || containsString(types, "number") {
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	if containsString(types, "boolean") {
+		switch value {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+	}
+	if containsString(types, "null") && value == "" {
+		return nil
+	}
+	return value
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedNames returns the keys of a decoded object in sorted order
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+const validationSpec = `{
+	"openapi": "3.0.0",
+	"paths": {
+		"/pets/{id}": {
+			"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "minimum": 1}}],
+			"put": {
+				"parameters": [
+					{"name": "dryRun", "in": "query", "schema": {"type": "boolean"}},
+					{"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "string", "enum": ["a", "b"]}}},
+					{"name": "X-Api-Key", "in": "header", "required": true, "schema": {"type": "string"}}
+				],
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {
+						"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}
+					}}}
+				}
+			}
+		}
+	}
+}`
+
+func TestClient_Forward_Validation(t *testing.T) {
+	forwarded := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		specs: map[string]json.RawMessage{"pets": json.RawMessage(validationSpec)},
+		configs: map[string]*storage.ServiceConfig{
+			"pets": {BaseURL: backend.URL, AuthHeaders: map[string]string{"X-Api-Key": "k"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		client    *Client
+		req       Request
+		want      []string // Violation pointers; nil means the request is forwarded
+		forwarded bool
+	}{
+		{
+			name:      "valid request",
+			client:    NewClient(store, WithRequestValidation(true)),
+			req:       Request{Path: "/pets/7?dryRun=true&tags=a,b", Body: json.RawMessage(`{"name":"Rex"}`)},
+			forwarded: true,
+		},
+		{
+			name:   "invalid parameters and body",
+			client: NewClient(store, WithRequestValidation(true)),
+			req:    Request{Path: "/pets/0?dryRun=maybe&tags=c", Body: json.RawMessage(`{"name":1}`)},
+			want:   []string{"/query/dryRun", "/query/tags/0", "/path/id", "/body/name"},
+		},
+		{
+			name:   "missing body",
+			client: NewClient(store, WithRequestValidation(true)),
+			req:    Request{Path: "/pets/7"},
+			want:   []string{"/body"},
+		},
+		{
+			name:   "unknown operation",
+			client: NewClient(store, WithRequestValidation(true)),
+			req:    Request{Path: "/owners", Body: json.RawMessage(`{}`)},
+			want:   []string{""},
+		},
+		{
+			name:   "per-request opt in",
+			client: NewClient(store),
+			req:    Request{Path: "/pets/0", Body: json.RawMessage(`{"name":"Rex"}`), Validate: true},
+			want:   []string{"/path/id"},
+		},
+		{
+			name:      "off by default",
+			client:    NewClient(store),
+			req:       Request{Path: "/pets/0", Body: json.RawMessage(`{}`)},
+			forwarded: true,
+		},
+		{
+			name:      "force bypasses validation",
+			client:    NewClient(store, WithRequestValidation(true)),
+			req:       Request{Path: "/pets/0", Body: json.RawMessage(`{}`), Force: true},
+			forwarded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forwarded = 0
+			tt.req.Service = "pets"
+			tt.req.Method = http.MethodPut
+
+			_, err := tt.client.Forward(context.Background(), &tt.req)
+
+			if tt.forwarded {
+				if err != nil {
+					t.Fatalf("Forward() failed: %v", err)
+				}
+				if forwarded != 1 {
+					t.Errorf("expected request to be forwarded once, got %d", forwarded)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if forwarded != 0 {
+				t.Error("expected invalid request not to be forwarded")
+			}
+			if len(validationErr.Violations) != len(tt.want) {
+				t.Fatalf("expected %d violations, got %+v", len(tt.want), validationErr.Violations)
+			}
+			for i, pointer := range tt.want {
+				if validationErr.Violations[i].Pointer != pointer {
+					t.Errorf("violation %d: expected pointer %q, got %q (%s)", i, pointer,
+						validationErr.Violations[i].Pointer, validationErr.Violations[i].Message)
+				}
+			}
+		})
+	}
+}
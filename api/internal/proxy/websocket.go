@@ -0,0 +1,179 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultWebSocketIdleTimeout closes a relayed WebSocket after this long without traffic either way
+	DefaultWebSocketIdleTimeout = 5 * time.Minute
+
+	// DefaultWebSocketMaxDuration closes a relayed WebSocket this long after it opened
+	DefaultWebSocketMaxDuration = time.Hour
+)
+
+var (
+	// ErrWebSocketIdle is returned by RelayWebSocket when neither side sent anything within the idle timeout
+	ErrWebSocketIdle = errors.New("websocket idle timeout")
+
+	// ErrWebSocketMaxDuration is returned by RelayWebSocket when the connection outlived its maximum duration
+	ErrWebSocketMaxDuration = errors.New("websocket maximum duration reached")
+)
+
+// WithWebSocketLimits sets how long a relayed WebSocket may stay idle and open in total
+// Zero keeps the respective default.
+func WithWebSocketLimits(idle, maxDuration time.Duration) Option {
+	return func(c *Client) {
+		if idle > 0 {
+			c.wsIdleTimeout = idle
+		}
+		if maxDuration > 0 {
+			c.wsMaxDuration = maxDuration
+		}
+	}
+}
+
+// DialWebSocket sends a client's WebSocket handshake to the service like Stream sends a request
+// req.Raw carries the client's handshake headers, e.g. Sec-WebSocket-Key, which go upstream
+// unchanged so the backend's answer is valid for the client. On 101 Switching Protocols the
+// response Body is the upgraded connection and also an io.Writer, ready for RelayWebSocket;
+// any other status is the backend refusing the upgrade and is streamed like Stream's.
+func (c *Client) DialWebSocket(ctx context.Context, req *Request) (*StreamResponse, error) {
+	if req.Method != http.MethodGet {
+		return nil, invalidRequest("websocket handshakes must use GET, got %s", req.Method)
+	}
+	handshake := *req
+	if handshake.Raw == nil {
+		handshake.Raw = &RawRequest{}
+	}
+
+	call, err := c.prepare(ctx, &handshake, true)
+	if err != nil {
+		return nil, err
+	}
+	// Hop-by-hop headers are dropped on the way, so the upgrade is asked for again
+	call.httpReq.Header.Set("Connection", "Upgrade")
+	call.httpReq.Header.Set("Upgrade", "websocket")
+
+	ex, attempts, err := c.send(call, 0)
+	call.stopHeaderTimer()
+	if err != nil {
+		call.cancel(nil)
+		return nil, err
+	}
+
+	header := ex.resp.Header.Clone()
+	removeHopHeaders(header)
+
+	resp := &StreamResponse{
+		StatusCode: ex.resp.StatusCode,
+		Header:     header,
+		Body:       &streamBody{ReadCloser: ex.resp.Body, cancel: call.cancel},
+		Attempts:   attempts,
+	}
+	if ex.resp.StatusCode == http.StatusSwitchingProtocols {
+		conn, ok := ex.resp.Body.(io.ReadWriteCloser)
+		if !ok {
+			ex.resp.Body.Close()
+			call.cancel(nil)
+			return nil, fmt.Errorf("%w: upgraded connection is not writable", ErrUpstreamResponse)
+		}
+		resp.Body = &upgradedConn{ReadWriteCloser: conn, cancel: call.cancel}
+	}
+	return resp, nil
+}
+
+// upgradedConn releases the call's context along with the upgraded connection
+type upgradedConn struct {
+	io.ReadWriteCloser
+	cancel context.CancelCauseFunc
+}
+
+func (c *upgradedConn) Close() error {
+	err := c.ReadWriteCloser.Close()
+	c.cancel(nil)
+	return err
+}
+
+// RelayWebSocket copies frames both ways between a client and an upgraded upstream connection
+// Frames pass through byte for byte, so extensions and subprotocols are negotiated end to end.
+// It returns nil once either side closes, ErrWebSocketIdle or ErrWebSocketMaxDuration when a
+// limit ends the relay, or ctx's error; both connections are closed in every case.
+func (c *Client) RelayWebSocket(ctx context.Context, client, upstream io.ReadWriteCloser) error {
+	var lastActive atomic.Int64
+	lastActive.Store(time.Now().UnixNano())
+
+	done := make(chan struct{}, 2)
+	pipe := func(dst io.Writer, src io.Reader) {
+		io.Copy(&activityWriter{w: dst, lastActive: &lastActive}, src)
+		done <- struct{}{}
+	}
+	go pipe(upstream, client)
+	go pipe(client, upstream)
+
+	idle := time.NewTimer(c.wsIdleTimeout)
+	defer idle.Stop()
+	total := time.NewTimer(c.wsMaxDuration)
+	defer total.Stop()
+
+	var err error
+	finished := 0
+loop:
+	for {
+		select {
+		case <-done:
+			finished++
+			break loop
+		case <-idle.C:
+			quiet := time.Since(time.Unix(0, lastActive.Load()))
+			if quiet >= c.wsIdleTimeout {
+				err = ErrWebSocketIdle
+				break loop
+			}
+			idle.Reset(c.wsIdleTimeout - quiet)
+		case <-total.C:
+			err = ErrWebSocketMaxDuration
+			break loop
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		}
+	}
+
+	// Closing both ends unblocks whichever copy is still running
+	client.Close()
+	upstream.Close()
+	for ; finished < 2; finished++ {
+		<-done
+	}
+	return err
+}
+
+// activityWriter records when data last went through it
+type activityWriter struct {
+	w          io.Writer
+	lastActive *atomic.Int64
+}
+
+func (a *activityWriter) Write(p []byte) (int, error) {
+	a.lastActive.Store(time.Now().UnixNano())
+	return a.w.Write(p)
+}
+
+// webSocketTarget maps a ws(s) target URL onto the http(s) URL its handshake is sent to
+func webSocketTarget(targetURL string) string {
+	switch {
+	case strings.HasPrefix(targetURL, "ws://"):
+		return "http://" + strings.TrimPrefix(targetURL, "ws://")
+	case strings.HasPrefix(targetURL, "wss://"):
+		return "https://" + strings.TrimPrefix(targetURL, "wss://")
+	}
+	return targetURL
+}
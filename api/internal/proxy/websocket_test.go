@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"jonathanmcclement.com/playground/internal/storage"
+)
+
+// newRawEchoBackend accepts any upgrade and echoes bytes back; frames are opaque to the relay
+func newRawEchoBackend(t *testing.T, seen *http.Header) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			http.Error(w, "upgrade required", http.StatusUpgradeRequired)
+			return
+		}
+		*seen = r.Header.Clone()
+
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: accepted\r\n\r\n")
+		rw.Flush()
+		io.Copy(conn, rw)
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func TestClient_DialWebSocket(t *testing.T) {
+	var seen http.Header
+	backend := newRawEchoBackend(t, &seen)
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{
+			"chat": {
+				BaseURL:     "ws" + strings.TrimPrefix(backend.URL, "http"),
+				AuthHeaders: map[string]string{"X-Api-Key": "secret"},
+			},
+		},
+	}
+	client := NewClient(store)
+
+	resp, err := client.DialWebSocket(context.Background(), &Request{
+		Service: "chat",
+		Method:  http.MethodGet,
+		Path:    "/socket",
+		Raw:     &RawRequest{Header: http.Header{"Sec-Websocket-Key": {"dGhlIHNhbXBsZSBub25jZQ=="}, "Sec-Websocket-Version": {"13"}}},
+	})
+	if err != nil {
+		t.Fatalf("DialWebSocket() failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != "accepted" {
+		t.Errorf("expected the backend's handshake headers, got %v", resp.Header)
+	}
+	if seen.Get("X-Api-Key") != "secret" || seen.Get("Sec-WebSocket-Key") != "dGhlIHNhbXBsZSBub25jZQ==" {
+		t.Errorf("expected auth and handshake headers upstream, got %v", seen)
+	}
+
+	upstream, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		t.Fatalf("expected a writable upgraded connection, got %T", resp.Body)
+	}
+
+	clientSide, proxySide := net.Pipe()
+	relayed := make(chan error, 1)
+	go func() { relayed <- client.RelayWebSocket(context.Background(), proxySide, upstream) }()
+
+	if _, err := clientSide.Write([]byte("hello")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	echo := make([]byte, 5)
+	if _, err := io.ReadFull(clientSide, echo); err != nil || string(echo) != "hello" {
+		t.Fatalf("expected the echo, got %q (%v)", echo, err)
+	}
+
+	clientSide.Close()
+	select {
+	case err := <-relayed:
+		if err != nil {
+			t.Errorf("expected a clean close, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("relay did not stop after the client closed")
+	}
+}
+
+func TestClient_DialWebSocket_Refused(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer backend.Close()
+
+	store := &mockSpecStore{
+		configs: map[string]*storage.ServiceConfig{"chat": {BaseURL: backend.URL}},
+	}
+	client := NewClient(store)
+
+	if _, err := client.DialWebSocket(context.Background(), &Request{Service: "chat", Method: http.MethodPost, Path: "/socket"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("expected ErrInvalidRequest for a POST handshake, got %v", err)
+	}
+
+	// A backend that does not switch protocols answers like any streamed response
+	resp, err := client.DialWebSocket(context.Background(), &Request{Service: "chat", Method: http.MethodGet, Path: "/socket"})
+	if err != nil {
+		t.Fatalf("DialWebSocket() failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected the backend's 403, got %d", resp.StatusCode)
+	}
+	if body, _ := io.ReadAll(resp.Body); strings.TrimSpace(string(body)) != "forbidden" {
+		t.Errorf("expected the backend's body, got %q", body)
+	}
+}
+
+func TestClient_RelayWebSocket_Limits(t *testing.T) {
+	tests := []struct {
+		name        string
+		idle        time.Duration
+		maxDuration time.Duration
+		chatter     bool // Keep sending so only the total duration can end the relay
+		want        error
+	}{
+		{name: "idle", idle: 50 * time.Millisecond, maxDuration: time.Minute, want: ErrWebSocketIdle},
+		{name: "max duration", idle: time.Minute, maxDuration: 150 * time.Millisecond, chatter: true, want: ErrWebSocketMaxDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(&mockSpecStore{}, WithWebSocketLimits(tt.idle, tt.maxDuration))
+
+			clientSide, proxySide := net.Pipe()
+			upstreamSide, proxyUpstream := net.Pipe()
+			go io.Copy(io.Discard, upstreamSide)
+
+			if tt.chatter {
+				go func() {
+					for {
+						if _, err := clientSide.Write([]byte("ping")); err != nil {
+							return
+						}
+						time.Sleep(10 * time.Millisecond)
+					}
+				}()
+			}
+
+			start := time.Now()
+			err := client.RelayWebSocket(context.Background(), proxySide, proxyUpstream)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("relay took %v to enforce the limit", elapsed)
+			}
+
+			// Both ends are closed once the relay gives up
+			if _, err := clientSide.Read(make([]byte, 1)); err == nil {
+				t.Error("expected the client connection to be closed")
+			}
+		})
+	}
+}
+
+func TestWebSocketTarget(t *testing.T) {
+	tests := map[string]string{
+		"ws://chat.example.com/socket":   "http://chat.example.com/socket",
+		"wss://chat.example.com/socket":  "https://chat.example.com/socket",
+		"https://api.example.com/events": "https://api.example.com/events",
+	}
+	for in, want := range tests {
+		if got := webSocketTarget(in); got != want {
+			t.Errorf("webSocketTarget(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxRefDepth bounds how many $refs can be expanded inside one another
+const maxRefDepth = 64
+
+// bundler inlines relative $refs to other files so a spec is served as a single document
+// Refs into the entry file are kept as local refs. A ref that points back to something
+// already being expanded (a cycle) becomes a local ref to where that target is inlined.
+// Another document's x-proxy-config is never inlined, since it holds that service's secrets.
+type bundler struct {
+	root      string                     // Specs directory, paths in errors are relative to it
+	scope     string                     // Files may only be referenced from within this directory, none when empty
+	realScope string                     // scope with symlinks resolved, to check where links lead
+	entryFile string                     // Absolute path of the entry document
+	files     map[string]json.RawMessage // Parsed files by absolute path
+	depth     int                        // Number of refs currently being expanded
+	inlinedAt map[string]string          // Output pointer of each target being expanded, by file#pointer
+}
+
+// bundleRefs resolves relative $refs in the entry document found at path
+// Referenced files must lie under scope, normally root itself; an empty scope allows none.
+func bundleRefs(data []byte, path, root, scope string) (json.RawMessage, error) {
+	realScope := scope
+	if scope != "" {
+		scope = filepath.Clean(scope)
+		if resolved, err := filepath.EvalSymlinks(scope); err == nil {
+			realScope = resolved
+		}
+	}
+	b := &bundler{
+		root:      filepath.Clean(root),
+		scope:     scope,
+		realScope: filepath.Clean(realScope),
+		entryFile: filepath.Clean(path),
+		files:     map[string]json.RawMessage{filepath.Clean(path): data},
+		inlinedAt: make(map[string]string),
+	}
+	return b.walk(data, b.entryFile, "")
+}
+
+// walk rebuilds a JSON value, expanding refs found in it
+// file is the document the value came from, out is its JSON pointer in the bundled output
+func (b *bundler) walk(raw json.RawMessage, file, out string) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	switch raw[0] {
+	case '{':
+		fields, err := decodeObject(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range fields {
+			if f.key != "$ref" {
+				continue
+			}
+			var ref string
+			if err := json.Unmarshal(f.value, &ref); err != nil {
+				break // Not a reference, e.g. a schema property named $ref
+			}
+			if expanded, ok, err := b.expand(ref, file, out); ok || err != nil {
+				return expanded, err
+			}
+		}
+
+		for i, f := range fields {
+			value, err := b.walk(f.value, file, out+"/"+escapePointer(f.key))
+			if err != nil {
+				return nil, err
+			}
+			fields[i].value = value
+		}
+		return encodeObject(fields)
+
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			value, err := b.walk(item, file, out+"/"+strconv.Itoa(i))
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(value)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+
+	default:
+		return raw, nil
+	}
+}
+
+// expand replaces a $ref with the content it points to
+// Returns ok false when the ref should be left untouched (local to the entry file, or remote)
+func (b *bundler) expand(ref, file, out string) (json.RawMessage, bool, error) {
+	refPath, pointer, _ := strings.Cut(ref, "#")
+
+	// Remote refs are not fetched
+	if u, err := url.Parse(refPath); err == nil && u.Scheme != "" {
+		return nil, false, nil
+	}
+
+	target := file
+	if refPath != "" {
+		target = filepath.Clean(filepath.Join(filepath.Dir(file), filepath.FromSlash(refPath)))
+	}
+
+	// The entry document is the root of the bundle, so refs into it become local refs;
+	// its own x-proxy-config is replaced by the public summary, so nothing may point into it
+	if target == b.entryFile {
+		if tokens, ok := pointerTokens(pointer); ok && len(tokens) > 0 && tokens[0] == proxyConfigKey {
+			return nil, false, fmt.Errorf("$ref %q in %s: %s cannot be referenced", ref, b.rel(file), proxyConfigKey)
+		}
+		if refPath == "" {
+			return nil, false, nil
+		}
+		local, err := localRef(pointer)
+		return local, err == nil, err
+	}
+
+	key := target + "#" + pointer
+	if at, onStack := b.inlinedAt[key]; onStack {
+		local, err := localRef(at)
+		return local, err == nil, err
+	}
+	if b.depth >= maxRefDepth {
+		return nil, false, fmt.Errorf("$ref chain too deep at %q in %s", ref, b.rel(file))
+	}
+
+	doc, err := b.load(target)
+	if err != nil {
+		return nil, false, fmt.Errorf("$ref %q in %s: %w", ref, b.rel(file), err)
+	}
+
+	node, ok := rawPointer(doc, pointer)
+	if !ok {
+		return nil, false, fmt.Errorf("$ref %q in %s: pointer %q not found in %s", ref, b.rel(file), pointer, b.rel(target))
+	}
+	if refsProxyConfig(doc, pointer) {
+		return nil, false, fmt.Errorf("$ref %q in %s: %s of %s cannot be referenced", ref, b.rel(file), proxyConfigKey, b.rel(target))
+	}
+
+	b.depth++
+	b.inlinedAt[key] = out
+	defer func() {
+		b.depth--
+		delete(b.inlinedAt, key)
+	}()
+
+	expanded, err := b.walk(node, target, out)
+	if err != nil {
+		return nil, false, err
+	}
+	return expanded, true, nil
+}
+
+// refsProxyConfig reports whether a pointer into doc reaches its x-proxy-config, either by
+// pointing into it or by taking the whole document that holds it
+func refsProxyConfig(doc json.RawMessage, pointer string) bool {
+	tokens, ok := pointerTokens(pointer)
+	if !ok {
+		return false
+	}
+	if len(tokens) > 0 {
+		return tokens[0] == proxyConfigKey
+	}
+	_, found := rawPointer(doc, "/"+escapePointer(proxyConfigKey))
+	return found
+}
+
+// localRef builds a {"$ref": "#pointer"} object
+func localRef(pointer string) (json.RawMessage, error) {
+	return json.Marshal(map[string]string{"$ref": "#" + pointer})
+}
+
+// load reads and caches a referenced file, converting YAML to JSON
+func (b *bundler) load(path string) (json.RawMessage, error) {
+	if doc, ok := b.files[path]; ok {
+		return doc, nil
+	}
+
+	rel := b.rel(path)
+	switch {
+	case b.scope == "":
+		return nil, fmt.Errorf("refs to other files are not allowed here")
+	case !withinDir(b.scope, path):
+		return nil, fmt.Errorf("file is outside the %s", b.scopeName())
+	}
+
+	// A symlink inside the scope may still lead out of it
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	if !withinDir(b.realScope, resolved) {
+		return nil, fmt.Errorf("file is outside the %s", b.scopeName())
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	if isYAMLFile(path) {
+		data, err = yamlToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML in %s: %w", rel, err)
+		}
+	} else if !json.Valid(data) {
+		return nil, fmt.Errorf("invalid JSON in %s", rel)
+	}
+
+	b.files[path] = data
+	return data, nil
+}
+
+// scopeName names the directory refs are confined to, for error messages
+func (b *bundler) scopeName() string {
+	if b.scope == b.root {
+		return "specs directory"
+	}
+	return "service directory"
+}
+
+// withinDir reports whether path is dir or lies below it
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// rel shortens a path for error messages
+func (b *bundler) rel(path string) string {
+	if rel, err := filepath.Rel(b.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// rawPointer looks up an RFC 6901 JSON pointer in raw JSON without losing key order
+func rawPointer(raw json.RawMessage, pointer string) (json.RawMessage, bool) {
+	tokens, ok := pointerTokens(pointer)
+	if !ok {
+		return nil, false
+	}
+
+	node := bytes.TrimSpace(raw)
+	for _, token := range tokens {
+		if len(node) == 0 {
+			return nil, false
+		}
+
+		switch node[0] {
+		case '{':
+			fields, err := decodeObject(node)
+			if err != nil {
+				return nil, false
+			}
+			found := false
+			for _, f := range fields {
+				if f.key == token {
+					node, found = bytes.TrimSpace(f.value), true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case '[':
+			var items []json.RawMessage
+			if err := json.Unmarshal(node, &items); err != nil {
+				return nil, false
+			}
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(items) {
+				return nil, false
+			}
+			node = bytes.TrimSpace(items[i])
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// pointerTokens splits a $ref fragment, which may be percent-encoded, into unescaped RFC 6901 tokens
+func pointerTokens(pointer string) ([]string, bool) {
+	if pointer == "" {
+		return nil, true
+	}
+	if unescaped, err := url.PathUnescape(pointer); err == nil {
+		pointer = unescaped
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, true
+}
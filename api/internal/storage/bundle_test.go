@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files (relative path -> content) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestFileSpecStore_ServiceDirectories(t *testing.T) {
+	tempDir := t.TempDir()
+
+	writeFiles(t, tempDir, map[string]string{
+		"payments/openapi.yaml": `openapi: 3.0.0
+info: {title: Payments, version: "1"}
+paths:
+  /payments:
+    get:
+      responses:
+        "200":
+          $ref: components/responses.json#/Payments
+components:
+  schemas:
+    Money: {type: number}
+`,
+		"payments/components/responses.json": `{"Payments":{"description":"ok","content":{"application/json":{"schema":{"$ref":"schemas.yaml#/Payment"}}}}}`,
+		"payments/components/schemas.yaml": `Payment:
+  type: object
+  properties:
+    amount: {$ref: "../openapi.yaml#/components/schemas/Money"}
+    refunds:
+      type: array
+      items: {$ref: "#/Payment"}
+`,
+		// Fragment directory without an entry file is not a service
+		"shared/errors.json": `{"Error":{"type":"object"}}`,
+		"standalone.json":    `{"openapi":"3.0.0","paths":{"/a":{"get":{"responses":{"default":{"$ref":"shared/errors.json#/Error"}}}}}}`,
+	})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	names, _ := store.List()
+	if strings.Join(names, ",") != "payments,standalone" {
+		t.Errorf("expected payments,standalone, got %v", names)
+	}
+
+	spec, err := store.Get("payments")
+	if err != nil {
+		t.Fatalf("Get('payments') failed: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatalf("failed to unmarshal spec: %v", err)
+	}
+
+	responsePtr := "/paths/~1payments/get/responses/200"
+	response, ok := resolvePointer(doc, responsePtr)
+	if !ok {
+		t.Fatalf("expected bundled response at %s: %s", responsePtr, spec)
+	}
+	if response.(map[string]interface{})["description"] != "ok" {
+		t.Errorf("expected inlined response, got %v", response)
+	}
+
+	schemaPtr := responsePtr + "/content/application~1json/schema"
+
+	// Refs back into the entry file become local refs
+	amount, _ := resolvePointer(doc, schemaPtr+"/properties/amount/$ref")
+	if amount != "#/components/schemas/Money" {
+		t.Errorf("expected local ref to Money, got %v", amount)
+	}
+
+	// The recursive schema points at where it was inlined
+	items, _ := resolvePointer(doc, schemaPtr+"/properties/refunds/items/$ref")
+	if items != "#"+schemaPtr {
+		t.Errorf("expected cycle to become %q, got %v", "#"+schemaPtr, items)
+	}
+
+	// No external refs remain, so validation has nothing to warn about
+	diags, _ := store.Diagnostics("payments")
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics for bundled spec, got %+v", diags)
+	}
+
+	spec, _ = store.Get("standalone")
+	if !strings.Contains(string(spec), `"default":{"type":"object"}`) {
+		t.Errorf("expected top-level spec to bundle shared fragment, got %s", spec)
+	}
+}
+
+func TestFileSpecStore_BundleErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "missing file",
+			files: map[string]string{
+				"svc/openapi.json": `{"openapi":"3.0.0","paths":{"/a":{"$ref":"paths/a.json"}}}`,
+			},
+			want: "paths/a.json",
+		},
+		{
+			name: "missing pointer",
+			files: map[string]string{
+				"svc/openapi.json":  `{"openapi":"3.0.0","paths":{"/a":{"$ref":"paths.json#/nope"}}}`,
+				"svc/paths.json":    `{"a":{}}`,
+				"svc/unrelated.txt": "ignored",
+			},
+			want: "/nope",
+		},
+		{
+			name: "outside specs directory",
+			files: map[string]string{
+				"svc/openapi.json": `{"openapi":"3.0.0","paths":{"/a":{"$ref":"../../etc/passwd"}}}`,
+			},
+			want: "outside the specs directory",
+		},
+		{
+			name: "ref into another service's proxy config",
+			files: map[string]string{
+				"payments.json": `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://pay.example.com","authHeaders":{"Authorization":"Bearer secret"}}}`,
+				"evil.json":     `{"openapi":"3.0.0","components":{"schemas":{"leak":{"$ref":"payments.json#/x-proxy-config/authHeaders"}}}}`,
+			},
+			want: "x-proxy-config of payments.json cannot be referenced",
+		},
+		{
+			name: "percent-encoded ref into a proxy config",
+			files: map[string]string{
+				"payments.json": `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://pay.example.com"}}`,
+				"evil.json":     `{"openapi":"3.0.0","components":{"schemas":{"leak":{"$ref":"payments.json#/x%2Dproxy-config"}}}}`,
+			},
+			want: "cannot be referenced",
+		},
+		{
+			name: "whole document holding a proxy config",
+			files: map[string]string{
+				"payments.json": `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://pay.example.com"}}`,
+				"evil.json":     `{"openapi":"3.0.0","components":{"schemas":{"leak":{"$ref":"payments.json"}}}}`,
+			},
+			want: "cannot be referenced",
+		},
+		{
+			name: "two entry files",
+			files: map[string]string{
+				"svc/openapi.json": `{"openapi":"3.0.0"}`,
+				"svc/openapi.yaml": `openapi: 3.0.0`,
+			},
+			want: "duplicate spec files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			specsDir := filepath.Join(tempDir, "specs")
+			writeFiles(t, specsDir, tt.files)
+
+			_, err := NewFileSpecStore(specsDir)
+			if err == nil {
+				t.Fatal("expected load error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error to mention %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFileSpecStore_BundleSymlinkOutsideRoot(t *testing.T) {
+	tempDir := t.TempDir()
+	specsDir := filepath.Join(tempDir, "specs")
+	writeFiles(t, tempDir, map[string]string{
+		"secret.json":            `{"token":"outside"}`,
+		"specs/svc/openapi.json": `{"openapi":"3.0.0","components":{"schemas":{"leak":{"$ref":"link.json#/token"}}}}`,
+	})
+	if err := os.Symlink(filepath.Join(tempDir, "secret.json"), filepath.Join(specsDir, "svc", "link.json")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	_, err := NewFileSpecStore(specsDir)
+	if err == nil || !strings.Contains(err.Error(), "outside the specs directory") {
+		t.Fatalf("expected an outside the specs directory error, got %v", err)
+	}
+}
+
+func TestFileSpecStore_NestedProxyConfigRedacted(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"shared/fragments.json": `{"Thing":{"type":"object","x-proxy-config":{"authHeaders":{"Authorization":"Bearer nested-secret"}}}}`,
+		"svc.json":              `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://svc.example.com"},"components":{"schemas":{"thing":{"$ref":"shared/fragments.json#/Thing"}}}}`,
+	})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	spec, _ := store.Get("svc")
+	if strings.Contains(string(spec), "nested-secret") {
+		t.Errorf("expected nested x-proxy-config to be redacted, got %s", spec)
+	}
+	if !strings.Contains(string(spec), `"thing":{"type":"object"}`) {
+		t.Errorf("expected the rest of the fragment to be inlined, got %s", spec)
+	}
+	if !strings.Contains(string(spec), `"x-proxy-config":{"baseURL":"https://svc.example.com"}`) {
+		t.Errorf("expected the root config summary to remain, got %s", spec)
+	}
+}
+
+func TestRawPointer(t *testing.T) {
+	raw := json.RawMessage(`{"a/b":{"list":[1,{"c~d":"x"}]}}`)
+
+	got, ok := rawPointer(raw, "/a~1b/list/1/c~0d")
+	if !ok || string(got) != `"x"` {
+		t.Errorf("expected \"x\", got %s (%v)", got, ok)
+	}
+
+	if _, ok := rawPointer(raw, "/a~1b/list/5"); ok {
+		t.Error("expected out of range index to fail")
+	}
+}
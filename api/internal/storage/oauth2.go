@@ -0,0 +1,66 @@
+package storage
+
+import "fmt"
+
+// OAuth2 grant types the proxy can use to obtain access tokens
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+)
+
+// OAuth2 client authentication styles for the token request
+const (
+	ClientAuthBasic = "basic" // HTTP Basic with the client id and secret
+	ClientAuthBody  = "body"  // client_id and client_secret form fields
+)
+
+// OAuth2Config lets the proxy obtain access tokens for a service instead of static auth headers
+// ClientID, ClientSecret and RefreshToken may use ${env:...} and ${file:...} placeholders.
+// With a RefreshToken the refresh_token grant is used, otherwise client_credentials.
+type OAuth2Config struct {
+	TokenURL     string   `json:"tokenURL"`
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	RefreshToken string   `json:"refreshToken,omitempty"`
+	ClientAuth   string   `json:"clientAuth,omitempty"` // basic (default) or body
+}
+
+// GrantType returns the grant the config uses
+func (c *OAuth2Config) GrantType() string {
+	if c.RefreshToken != "" {
+		return GrantRefreshToken
+	}
+	return GrantClientCredentials
+}
+
+// PublicOAuth2 is the redacted view of an OAuth2Config, without client credentials or tokens
+type PublicOAuth2 struct {
+	TokenURL  string   `json:"tokenURL"`
+	GrantType string   `json:"grantType"`
+	Scopes    []string `json:"scopes,omitempty"`
+}
+
+// publicOAuth2 summarizes an OAuth2 config, or returns nil when there is none
+func publicOAuth2(config *OAuth2Config) *PublicOAuth2 {
+	if config == nil {
+		return nil
+	}
+	return &PublicOAuth2{
+		TokenURL:  redactURL(config.TokenURL),
+		GrantType: config.GrantType(),
+		Scopes:    config.Scopes,
+	}
+}
+
+// resolveOAuth2Secrets resolves placeholders in an OAuth2 config's credentials in place
+func resolveOAuth2Secrets(config *OAuth2Config) error {
+	for _, value := range []*string{&config.ClientID, &config.ClientSecret, &config.RefreshToken} {
+		resolved, err := resolveSecrets(*value)
+		if err != nil {
+			return fmt.Errorf("oauth2: %w", err)
+		}
+		*value = resolved
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileSpecStore_OAuth2(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("PLAYGROUND_TEST_CLIENT_SECRET", "s3cret")
+
+	writeFiles(t, tempDir, map[string]string{
+		"billing.json": `{"openapi":"3.0.0","info":{"title":"Billing","version":"1"},"paths":{},
+			"x-proxy-config":{"baseURL":"https://billing.internal","oauth2":{
+				"tokenURL":"https://auth.internal/oauth/token","clientId":"playground",
+				"clientSecret":"${env:PLAYGROUND_TEST_CLIENT_SECRET}","scopes":["billing.read"]}}}`,
+	})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	config, err := store.GetConfig("billing")
+	if err != nil {
+		t.Fatalf("GetConfig() failed: %v", err)
+	}
+	if config.OAuth2 == nil || config.OAuth2.ClientSecret != "s3cret" {
+		t.Fatalf("expected the client secret to be resolved, got %+v", config.OAuth2)
+	}
+	if config.OAuth2.GrantType() != GrantClientCredentials {
+		t.Errorf("expected client_credentials grant, got %s", config.OAuth2.GrantType())
+	}
+
+	spec, err := store.Get("billing")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	for _, secret := range []string{"s3cret", "PLAYGROUND_TEST_CLIENT_SECRET", "playground"} {
+		if strings.Contains(string(spec), secret) {
+			t.Errorf("expected %q to be redacted from the served spec", secret)
+		}
+	}
+	if !strings.Contains(string(spec), `"grantType":"client_credentials"`) {
+		t.Errorf("expected an OAuth2 summary in the served spec, got %s", spec)
+	}
+}
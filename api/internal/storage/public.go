@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// proxyConfigKey is the OpenAPI extension holding the private proxy configuration
+const proxyConfigKey = "x-proxy-config"
+
+// PublicProxyConfig is the redacted view of a ServiceConfig that is safe to serve to browsers
+type PublicProxyConfig struct {
+	BaseURL            string              `json:"baseURL,omitempty"`
+	AuthHeaderNames    []string            `json:"authHeaderNames,omitempty"`
+	Environments       []PublicEnvironment `json:"environments,omitempty"`
+	DefaultEnvironment string              `json:"defaultEnvironment,omitempty"`
+	ConnectTimeout     string              `json:"connectTimeout,omitempty"`
+	ResponseTimeout    string              `json:"responseTimeout,omitempty"`
+	Retry              *RetryPolicy        `json:"retry,omitempty"`
+	TLS                *PublicTLS          `json:"tls,omitempty"`
+	RedirectHosts      []string            `json:"redirectHosts,omitempty"`
+	OAuth2             *PublicOAuth2       `json:"oauth2,omitempty"`
+	Signing            *PublicSigning      `json:"signing,omitempty"`
+}
+
+// publicConfig builds the redacted summary for a service config
+// Userinfo, query and fragment are dropped from the base URL since they may carry credentials
+func publicConfig(config *ServiceConfig) *PublicProxyConfig {
+	return &PublicProxyConfig{
+		BaseURL:            redactURL(config.BaseURL),
+		AuthHeaderNames:    headerNames(config.AuthHeaders),
+		Environments:       config.PublicEnvironments(),
+		DefaultEnvironment: config.DefaultEnvironment,
+		ConnectTimeout:     config.ConnectTimeout,
+		ResponseTimeout:    config.ResponseTimeout,
+		Retry:              config.Retry,
+		TLS:                publicTLS(config.TLS),
+		RedirectHosts:      config.RedirectHosts,
+		OAuth2:             publicOAuth2(config.OAuth2),
+		Signing:            publicSigning(config.Signing),
+	}
+}
+
+// headerNames returns the sorted names of a set of auth headers
+func headerNames(headers map[string]string) []string {
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// redactURL strips anything that could hold a secret from a URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// sanitizeSpec returns a copy of the spec with x-proxy-config replaced by its public summary
+// (or removed when config is nil). Top-level key order is preserved so the UI lists
+// paths and sections in the order they were authored. An x-proxy-config found deeper in the
+// bundled document, e.g. inlined from another file, is dropped outright.
+func sanitizeSpec(data []byte, config *ServiceConfig) (json.RawMessage, error) {
+	fields, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := fields[:0]
+	for _, f := range fields {
+		if f.key != proxyConfigKey {
+			value, err := dropProxyConfigs(f.value)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, field{key: f.key, value: value})
+			continue
+		}
+		if config == nil {
+			continue
+		}
+		pub, err := json.Marshal(publicConfig(config))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, field{key: proxyConfigKey, value: pub})
+	}
+
+	return encodeObject(out)
+}
+
+// dropProxyConfigs removes every x-proxy-config member from a JSON value, keeping key order
+func dropProxyConfigs(raw json.RawMessage) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	switch raw[0] {
+	case '{':
+		fields, err := decodeObject(raw)
+		if err != nil {
+			return nil, err
+		}
+		out := fields[:0]
+		for _, f := range fields {
+			if f.key == proxyConfigKey {
+				continue
+			}
+			value, err := dropProxyConfigs(f.value)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, field{key: f.key, value: value})
+		}
+		return encodeObject(out)
+
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			value, err := dropProxyConfigs(item)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(value)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+
+	default:
+		return raw, nil
+	}
+}
+
+// field is a single member of a JSON object with its raw value
+type field struct {
+	key   string
+	value json.RawMessage
+}
+
+// decodeObject splits a JSON object into its members, keeping their original order
+func decodeObject(data []byte) ([]field, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected JSON object")
+	}
+
+	var fields []field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected object key, got %v", tok)
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field{key: key, value: value})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// encodeObject writes members back out as a JSON object in the given order
+func encodeObject(fields []field) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(f.value)
+	}
+	buf.WriteByte('}')
+	return json.RawMessage(buf.Bytes()), nil
+}
@@ -0,0 +1,21 @@
+package storage
+
+import "time"
+
+// RetryPolicy tells the proxy how to retry a service's failed calls
+// Only idempotent methods are retried unless AllowNonIdempotent is set.
+type RetryPolicy struct {
+	MaxAttempts        int    `json:"maxAttempts"`                  // Including the first call
+	Backoff            string `json:"backoff,omitempty"`            // Delay before the first retry, doubled after each; Go duration
+	MaxBackoff         string `json:"maxBackoff,omitempty"`         // Cap on the delay, also applied to Retry-After
+	RetryOn            []int  `json:"retryOn,omitempty"`            // Status codes worth retrying; empty means 502, 503 and 504
+	NetworkErrors      bool   `json:"networkErrors,omitempty"`      // Also retry refused, reset and timed out connections
+	AllowNonIdempotent bool   `json:"allowNonIdempotent,omitempty"` // Retry POST and PATCH too
+}
+
+// Backoffs returns the policy's initial and maximum delay; unset or invalid values are zero
+func (p *RetryPolicy) Backoffs() (initial, limit time.Duration) {
+	initial, _ = parseTimeout(p.Backoff)
+	limit, _ = parseTimeout(p.MaxBackoff)
+	return initial, limit
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern matches ${env:NAME} and ${file:/path/to/secret} references
+var placeholderPattern = regexp.MustCompile(`\$\{(env|file):([^}]+)\}`)
+
+// resolveSecrets replaces placeholders in a config value with the referenced secret
+// Environment variables must be set; secret files are read with surrounding whitespace trimmed
+func resolveSecrets(value string) (string, error) {
+	var resolveErr error
+
+	resolved := placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+
+		parts := placeholderPattern.FindStringSubmatch(match)
+		source, ref := parts[1], strings.TrimSpace(parts[2])
+
+		switch source {
+		case "env":
+			secret, ok := os.LookupEnv(ref)
+			if !ok {
+				resolveErr = fmt.Errorf("environment variable %s is not set", ref)
+				return match
+			}
+			return secret
+		default: // file
+			data, err := os.ReadFile(ref)
+			if err != nil {
+				resolveErr = fmt.Errorf("secret file %s: %w", ref, err)
+				return match
+			}
+			return strings.TrimSpace(string(data))
+		}
+	})
+
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
+
+// resolveConfigSecrets resolves placeholders in every auth header of a config in place,
+// including those of each environment, and in OAuth2 and signing credentials
+func resolveConfigSecrets(config *ServiceConfig) error {
+	if err := resolveHeaderSecrets(config.AuthHeaders); err != nil {
+		return err
+	}
+	if config.OAuth2 != nil {
+		if err := resolveOAuth2Secrets(config.OAuth2); err != nil {
+			return err
+		}
+	}
+	if config.Signing != nil {
+		if err := resolveSigningSecrets(config.Signing); err != nil {
+			return err
+		}
+	}
+	for _, name := range config.EnvironmentNames() {
+		if err := resolveHeaderSecrets(config.Environments[name].AuthHeaders); err != nil {
+			return fmt.Errorf("environment %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resolveHeaderSecrets resolves placeholders in a set of auth headers in place
+func resolveHeaderSecrets(headers map[string]string) error {
+	for name, value := range headers {
+		resolved, err := resolveSecrets(value)
+		if err != nil {
+			return fmt.Errorf("auth header %s: %w", name, err)
+		}
+		headers[name] = resolved
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package storage
+
+import "fmt"
+
+// Built-in request signer types for x-proxy-config signing.type
+const (
+	SignerAWSSigV4 = "aws-sigv4"
+	SignerHMAC     = "hmac-sha256"
+	SignerBasic    = "basic"
+)
+
+// SigningConfig selects and configures the signer applied to a service's requests
+// Only the fields of the chosen type are used. Credentials may use ${env:...} and
+// ${file:...} placeholders; signer types other than the built-ins are passed through
+// for signers registered with the proxy.
+type SigningConfig struct {
+	Type string `json:"type"`
+
+	// aws-sigv4
+	AccessKeyID     string `json:"accessKeyId,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+	SessionToken    string `json:"sessionToken,omitempty"`
+	Region          string `json:"region,omitempty"`
+	Service         string `json:"service,omitempty"` // e.g. execute-api, s3
+
+	// hmac-sha256
+	Secret          string `json:"secret,omitempty"`
+	Header          string `json:"header,omitempty"`          // Header carrying the signature, defaults to X-Signature
+	CanonicalString string `json:"canonicalString,omitempty"` // Template of the signed string, see the proxy package
+	TimestampHeader string `json:"timestampHeader,omitempty"` // Header carrying {timestamp}, defaults to X-Timestamp
+	Encoding        string `json:"encoding,omitempty"`        // hex (default) or base64
+
+	// basic
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Options holds settings for signers registered outside the built-ins
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// PublicSigning is the redacted view of a SigningConfig: its type and non-secret scope only
+type PublicSigning struct {
+	Type    string `json:"type"`
+	Region  string `json:"region,omitempty"`
+	Service string `json:"service,omitempty"`
+	Header  string `json:"header,omitempty"`
+}
+
+// publicSigning summarizes a signing config, or returns nil when there is none
+func publicSigning(config *SigningConfig) *PublicSigning {
+	if config == nil {
+		return nil
+	}
+	return &PublicSigning{
+		Type:    config.Type,
+		Region:  config.Region,
+		Service: config.Service,
+		Header:  config.Header,
+	}
+}
+
+// resolveSigningSecrets resolves placeholders in a signing config's credentials in place
+func resolveSigningSecrets(config *SigningConfig) error {
+	for _, value := range []*string{
+		&config.AccessKeyID, &config.SecretAccessKey, &config.SessionToken,
+		&config.Secret, &config.Username, &config.Password,
+	} {
+		resolved, err := resolveSecrets(*value)
+		if err != nil {
+			return fmt.Errorf("signing: %w", err)
+		}
+		*value = resolved
+	}
+	for name, value := range config.Options {
+		resolved, err := resolveSecrets(value)
+		if err != nil {
+			return fmt.Errorf("signing option %s: %w", name, err)
+		}
+		config.Options[name] = resolved
+	}
+	return nil
+}
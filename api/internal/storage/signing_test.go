@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileSpecStore_Signing(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("PLAYGROUND_TEST_AWS_SECRET", "wJalrXUtnFEMI")
+
+	writeFiles(t, tempDir, map[string]string{
+		"orders.json": `{"openapi":"3.0.0","info":{"title":"Orders","version":"1"},"paths":{},
+			"x-proxy-config":{"baseURL":"https://orders.internal","signing":{
+				"type":"aws-sigv4","accessKeyId":"AKIDEXAMPLE",
+				"secretAccessKey":"${env:PLAYGROUND_TEST_AWS_SECRET}","region":"us-east-1","service":"execute-api"}}}`,
+	})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	config, err := store.GetConfig("orders")
+	if err != nil {
+		t.Fatalf("GetConfig() failed: %v", err)
+	}
+	if config.Signing == nil || config.Signing.SecretAccessKey != "wJalrXUtnFEMI" {
+		t.Fatalf("expected the secret key to be resolved, got %+v", config.Signing)
+	}
+
+	spec, err := store.Get("orders")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	for _, secret := range []string{"wJalrXUtnFEMI", "PLAYGROUND_TEST_AWS_SECRET", "AKIDEXAMPLE"} {
+		if strings.Contains(string(spec), secret) {
+			t.Errorf("expected %q to be redacted from the served spec", secret)
+		}
+	}
+	if !strings.Contains(string(spec), `"type":"aws-sigv4"`) {
+		t.Errorf("expected a signing summary in the served spec, got %s", spec)
+	}
+}
@@ -8,15 +8,28 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // ErrServiceNotFound is returned when a service is not found in the store
 var ErrServiceNotFound = errors.New("service not found")
 
 // ServiceConfig represents configuration for a backend service
+// AuthHeaders values may reference ${env:NAME} or ${file:/path} placeholders,
+// which are resolved when the spec is loaded. Environments optionally declare
+// named deployments; BaseURL may then be left empty.
 type ServiceConfig struct {
-	BaseURL     string            `json:"baseURL"`
-	AuthHeaders map[string]string `json:"authHeaders,omitempty"`
+	BaseURL            string                        `json:"baseURL,omitempty"`
+	AuthHeaders        map[string]string             `json:"authHeaders,omitempty"`
+	Environments       map[string]*EnvironmentConfig `json:"environments,omitempty"`
+	DefaultEnvironment string                        `json:"defaultEnvironment,omitempty"`
+	ConnectTimeout     string                        `json:"connectTimeout,omitempty"`  // Go duration, e.g. 5s
+	ResponseTimeout    string                        `json:"responseTimeout,omitempty"` // Go duration, e.g. 30s
+	Retry              *RetryPolicy                  `json:"retry,omitempty"`
+	TLS                *TLSConfig                    `json:"tls,omitempty"`
+	RedirectHosts      []string                      `json:"redirectHosts,omitempty"` // e.g. auth.example.com, *.example.com or *
+	OAuth2             *OAuth2Config                 `json:"oauth2,omitempty"`
+	Signing            *SigningConfig                `json:"signing,omitempty"`
 }
 
 // SpecStore defines the interface for spec storage
@@ -29,95 +42,373 @@ type SpecStore interface {
 
 	// GetConfig returns the proxy configuration for a service
 	GetConfig(serviceName string) (*ServiceConfig, error)
+
+	// GetOriginal returns the spec as authored, before any Swagger 2.0 conversion
+	GetOriginal(serviceName string) (json.RawMessage, error)
+
+	// Diagnostics returns the validation findings for a service's spec
+	Diagnostics(serviceName string) ([]Diagnostic, error)
+}
+
+// SpecWriter defines the interface for publishing specs at runtime
+type SpecWriter interface {
+	// Put validates and stores a spec, returning true if the service was created
+	// Returns ErrServiceExists when the service exists and overwrite is false
+	Put(serviceName string, data []byte, overwrite bool) (bool, error)
+
+	// Delete removes the spec for a service
+	Delete(serviceName string) error
 }
 
-// FileSpecStore implements SpecStore using file system
+// FileSpecStore implements SpecStore and SpecWriter using file system
+// Specs are swapped in atomically on reload, so readers always see a consistent set
 type FileSpecStore struct {
-	specsDir string
-	specs    map[string]json.RawMessage // In-memory cache of full specs
-	configs  map[string]*ServiceConfig  // In-memory cache of proxy configs
+	specsDir   string
+	validation ValidationMode
+
+	reloadMu sync.Mutex // Serializes reloads and guards loaded
+	loaded   string     // Fingerprint of the directory as of the last reload
+
+	mu      sync.RWMutex
+	specs   map[string]json.RawMessage // In-memory cache of public (sanitized) specs
+	configs map[string]*ServiceConfig  // In-memory cache of proxy configs
+	entries map[string]*specEntry      // Last good entry per service, kept when a file fails to load
+}
+
+// specEntry is everything loaded from a single spec file
+type specEntry struct {
+	file        string          // Entry file path within specsDir, e.g. pets.json or payments/openapi.yaml
+	dir         string          // Service directory within specsDir, empty for single-file specs
+	spec        json.RawMessage // Public (sanitized) spec
+	original    json.RawMessage // Public spec as authored, set only when it was converted from Swagger 2.0
+	config      *ServiceConfig  // Private proxy config, nil if absent
+	diagnostics []Diagnostic    // Validation findings for the spec
+}
+
+// Option configures a FileSpecStore
+type Option func(*FileSpecStore)
+
+// WithValidationMode sets whether specs with validation errors are rejected or loaded with warnings
+func WithValidationMode(mode ValidationMode) Option {
+	return func(s *FileSpecStore) {
+		s.validation = mode
+	}
 }
 
 // NewFileSpecStore creates a new file-based spec store
 // Loads all specs from specsDir into memory on initialization
-func NewFileSpecStore(specsDir string) (*FileSpecStore, error) {
+func NewFileSpecStore(specsDir string, opts ...Option) (*FileSpecStore, error) {
 	// Check if directory exists
 	if _, err := os.Stat(specsDir); os.IsNotExist(err) {
 		return nil, fmt.Errorf("specs directory does not exist: %s", specsDir)
 	}
 
 	store := &FileSpecStore{
-		specsDir: specsDir,
-		specs:    make(map[string]json.RawMessage),
-		configs:  make(map[string]*ServiceConfig),
+		specsDir:   specsDir,
+		validation: ValidationWarn,
+		specs:      make(map[string]json.RawMessage),
+		configs:    make(map[string]*ServiceConfig),
+		entries:    make(map[string]*specEntry),
+	}
+	for _, opt := range opts {
+		opt(store)
 	}
 
-	// Load all spec files
-	if err := store.loadSpecs(); err != nil {
+	// Load all spec files; unlike later reloads, any bad file fails startup
+	if err := store.Reload(); err != nil {
 		return nil, fmt.Errorf("failed to load specs: %w", err)
 	}
 
 	return store, nil
 }
 
-// loadSpecs loads all *.json files from the specs directory
-func (s *FileSpecStore) loadSpecs() error {
-	entries, err := os.ReadDir(s.specsDir)
+// Reload re-reads every spec file and atomically swaps in the result
+// A file that fails to load keeps its last good version; the errors are returned joined
+func (s *FileSpecStore) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	// Taken before reading so edits made mid-reload trigger another one
+	snapshot, err := s.fingerprint()
+	if err != nil {
+		return err
+	}
+
+	dirEntries, err := os.ReadDir(s.specsDir)
 	if err != nil {
 		return fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	for _, entry := range entries {
-		// Skip directories
-		if entry.IsDir() {
+	s.mu.RLock()
+	previous := s.entries
+	s.mu.RUnlock()
+
+	var errs []error
+	next := make(map[string]*specEntry)
+
+	// Group files by service name so duplicates across extensions are caught
+	files := make(map[string][]string)
+	for _, dirEntry := range dirEntries {
+		// A sub-directory is a service when it holds an openapi.* entry file;
+		// other directories (e.g. shared components) only serve as $ref targets
+		if dirEntry.IsDir() {
+			entries, err := s.entryFiles(dirEntry.Name())
+			if err != nil {
+				// Like a file that fails to parse, an unreadable directory keeps its last good version
+				errs = append(errs, err)
+				if prev, ok := previous[dirEntry.Name()]; ok {
+					next[dirEntry.Name()] = prev
+				}
+				continue
+			}
+			if len(entries) > 0 {
+				files[dirEntry.Name()] = append(files[dirEntry.Name()], entries...)
+			}
+			continue
+		}
+
+		// Only process spec files (.json, .yaml, .yml)
+		serviceName, ok := serviceNameFromFile(dirEntry.Name())
+		if !ok {
 			continue
 		}
+		files[serviceName] = append(files[serviceName], dirEntry.Name())
+	}
+
+	serviceNames := make([]string, 0, len(files))
+	for serviceName := range files {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+
+	for _, serviceName := range serviceNames {
+		names := files[serviceName]
+		var entry *specEntry
+		var err error
+		if len(names) > 1 {
+			err = fmt.Errorf("duplicate spec files for service %s: %s", serviceName, strings.Join(names, ", "))
+		} else {
+			entry, err = s.loadSpecFile(names[0])
+		}
 
-		// Only process .json files
-		if !strings.HasSuffix(entry.Name(), ".json") {
+		if err != nil {
+			errs = append(errs, err)
+			if prev, ok := previous[serviceName]; ok {
+				next[serviceName] = prev
+			}
 			continue
 		}
+		next[serviceName] = entry
+	}
+
+	s.swap(next)
+	s.loaded = snapshot
+
+	return errors.Join(errs...)
+}
 
-		// Extract service name (filename without .json extension)
-		serviceName := strings.TrimSuffix(entry.Name(), ".json")
+// swap replaces the in-memory caches with the given entries
+func (s *FileSpecStore) swap(entries map[string]*specEntry) {
+	specs := make(map[string]json.RawMessage, len(entries))
+	configs := make(map[string]*ServiceConfig, len(entries))
+	for name, entry := range entries {
+		specs[name] = entry.spec
+		if entry.config != nil {
+			configs[name] = entry.config
+		}
+	}
+
+	s.mu.Lock()
+	s.specs = specs
+	s.configs = configs
+	s.entries = entries
+	s.mu.Unlock()
+}
+
+// loadSpecFile reads and parses a single spec file from the specs directory
+func (s *FileSpecStore) loadSpecFile(name string) (*specEntry, error) {
+	// Read file
+	filePath := filepath.Join(s.specsDir, name)
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file %s: %w", name, err)
+	}
 
-		// Read file
-		filePath := filepath.Join(s.specsDir, entry.Name())
-		data, err := os.ReadFile(filePath)
+	entry, err := s.parseSpec(name, data, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, s.checkValidation(entry)
+}
+
+// checkValidation rejects entries with error diagnostics in strict mode
+func (s *FileSpecStore) checkValidation(entry *specEntry) error {
+	if s.validation != ValidationStrict || len(errorDiagnostics(entry.diagnostics)) == 0 {
+		return nil
+	}
+	return &ValidationError{File: entry.file, Diagnostics: entry.diagnostics}
+}
+
+// parseSpec turns the contents of a spec file into a store entry
+// name is the file's path relative to specsDir, used to resolve relative $refs.
+// Uploaded documents come from outside the host, so their x-proxy-config may not
+// reference the host's secrets, see checkUploadedConfig, and they may only $ref files
+// in their own service directory.
+func (s *FileSpecStore) parseSpec(name string, data []byte, uploaded bool) (*specEntry, error) {
+	var err error
+
+	// YAML specs are converted up front so they are served as JSON
+	if isYAMLFile(name) {
+		data, err = yamlToJSON(data)
 		if err != nil {
-			return fmt.Errorf("failed to read spec file %s: %w", entry.Name(), err)
+			return nil, fmt.Errorf("invalid YAML in spec file %s: %w", name, err)
 		}
+	}
 
-		// Parse spec to extract proxy config
-		var specDoc map[string]interface{}
+	// Parse spec to extract proxy config
+	var specDoc map[string]interface{}
+	if err := json.Unmarshal(data, &specDoc); err != nil {
+		return nil, fmt.Errorf("invalid JSON in spec file %s: %w", name, err)
+	}
+
+	if uploaded {
+		if err := checkUploadedConfig(specDoc[proxyConfigKey]); err != nil {
+			return nil, err
+		}
+	}
+
+	var dir string
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		dir = name[:i]
+	}
+
+	// Inline relative $refs to other files so the spec is a single document
+	// Uploads may only reference files of their own service directory, never other services'
+	scope := s.specsDir
+	if uploaded {
+		scope = ""
+		if dir != "" {
+			scope = filepath.Join(s.specsDir, filepath.FromSlash(dir))
+		}
+	}
+	data, err = bundleRefs(data, filepath.Join(s.specsDir, filepath.FromSlash(name)), s.specsDir, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bundle spec file %s: %w", name, err)
+	}
+	specDoc = nil
+	if err := json.Unmarshal(data, &specDoc); err != nil {
+		return nil, fmt.Errorf("invalid bundled spec %s: %w", name, err)
+	}
+
+	// Swagger 2.0 documents are served as OpenAPI 3 so the UI only needs one parser
+	var original []byte
+	if isSwagger2(specDoc) {
+		original = data
+		data, err = convertSwagger2(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert Swagger 2.0 spec file %s: %w", name, err)
+		}
+		specDoc = nil
 		if err := json.Unmarshal(data, &specDoc); err != nil {
-			return fmt.Errorf("invalid JSON in spec file %s: %w", entry.Name(), err)
+			return nil, fmt.Errorf("invalid converted spec %s: %w", name, err)
 		}
+	}
 
-		// Extract x-proxy-config if present
-		if proxyConfigRaw, exists := specDoc["x-proxy-config"]; exists {
-			configBytes, err := json.Marshal(proxyConfigRaw)
-			if err != nil {
-				return fmt.Errorf("failed to marshal proxy config from %s: %w", entry.Name(), err)
-			}
+	// Extract x-proxy-config if present
+	var config *ServiceConfig
+	if proxyConfigRaw, exists := specDoc[proxyConfigKey]; exists {
+		configBytes, err := json.Marshal(proxyConfigRaw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal proxy config from %s: %w", name, err)
+		}
+
+		config = &ServiceConfig{}
+		if err := json.Unmarshal(configBytes, config); err != nil {
+			return nil, fmt.Errorf("invalid proxy config in spec file %s: %w", name, err)
+		}
 
-			var config ServiceConfig
-			if err := json.Unmarshal(configBytes, &config); err != nil {
-				return fmt.Errorf("invalid proxy config in spec file %s: %w", entry.Name(), err)
+		// Resolve ${env:...} and ${file:...} placeholders so secrets stay out of the spec
+		if err := resolveConfigSecrets(config); err != nil {
+			return nil, fmt.Errorf("failed to resolve secrets in spec file %s: %w", name, err)
+		}
+		if config.TLS != nil {
+			dir := filepath.Dir(filepath.Join(s.specsDir, filepath.FromSlash(name)))
+			if err := resolveTLSPaths(config.TLS, dir); err != nil {
+				return nil, fmt.Errorf("failed to resolve TLS files in spec file %s: %w", name, err)
 			}
+		}
+	}
 
-			s.configs[serviceName] = &config
+	// Keep a public copy for serving; secrets stay in the config only
+	publicSpec, err := sanitizeSpec(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sanitize spec file %s: %w", name, err)
+	}
+	var publicOriginal json.RawMessage
+	if original != nil {
+		if publicOriginal, err = sanitizeSpec(original, config); err != nil {
+			return nil, fmt.Errorf("failed to sanitize spec file %s: %w", name, err)
 		}
+	}
+
+	return &specEntry{
+		file:        name,
+		dir:         dir,
+		spec:        publicSpec,
+		original:    publicOriginal,
+		config:      config,
+		diagnostics: validateSpec(specDoc),
+	}, nil
+}
 
-		// Store full spec in memory
-		s.specs[serviceName] = json.RawMessage(data)
+// entryFiles lists the openapi.* entry files in a service directory (relative to specsDir)
+func (s *FileSpecStore) entryFiles(dir string) ([]string, error) {
+	dirEntries, err := os.ReadDir(filepath.Join(s.specsDir, dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service directory %s: %w", dir, err)
 	}
 
-	return nil
+	var names []string
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			continue
+		}
+		if base, ok := serviceNameFromFile(dirEntry.Name()); ok && base == entryFileBase {
+			names = append(names, dir+"/"+dirEntry.Name())
+		}
+	}
+	return names, nil
+}
+
+// entryFileBase is the file name (without extension) of a service directory's entry document
+const entryFileBase = "openapi"
+
+// specExtensions are the file extensions loaded as specs
+var specExtensions = []string{".json", ".yaml", ".yml"}
+
+// serviceNameFromFile returns the service name for a spec file (file name without extension)
+func serviceNameFromFile(name string) (string, bool) {
+	ext := filepath.Ext(name)
+	for _, specExt := range specExtensions {
+		if ext == specExt {
+			return strings.TrimSuffix(name, ext), true
+		}
+	}
+	return "", false
+}
+
+// isYAMLFile reports whether a spec file is authored in YAML
+func isYAMLFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
 }
 
 // List returns sorted list of service names
 func (s *FileSpecStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	names := make([]string, 0, len(s.specs))
 	for name := range s.specs {
 		names = append(names, name)
@@ -126,8 +417,12 @@ func (s *FileSpecStore) List() ([]string, error) {
 	return names, nil
 }
 
-// Get returns the spec for a service, or error if not found
+// Get returns the public spec for a service, or error if not found
+// The x-proxy-config block is replaced by its sanitized PublicProxyConfig view, see publicConfig
 func (s *FileSpecStore) Get(serviceName string) (json.RawMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	spec, exists := s.specs[serviceName]
 	if !exists {
 		return nil, fmt.Errorf("spec not found for service: %s", serviceName)
@@ -135,8 +430,42 @@ func (s *FileSpecStore) Get(serviceName string) (json.RawMessage, error) {
 	return spec, nil
 }
 
+// GetOriginal returns the public spec as authored, or error if not found
+// It differs from Get only for Swagger 2.0 specs, which Get serves converted to OpenAPI 3
+func (s *FileSpecStore) GetOriginal(serviceName string) (json.RawMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.entries[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("spec not found for service: %s", serviceName)
+	}
+	if entry.original != nil {
+		return entry.original, nil
+	}
+	return entry.spec, nil
+}
+
+// Diagnostics returns the validation findings for a service, or error if not found
+func (s *FileSpecStore) Diagnostics(serviceName string) ([]Diagnostic, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.entries[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("spec not found for service: %s", serviceName)
+	}
+	if entry.diagnostics == nil {
+		return []Diagnostic{}, nil
+	}
+	return entry.diagnostics, nil
+}
+
 // GetConfig returns the proxy configuration for a service, or error if not found
 func (s *FileSpecStore) GetConfig(serviceName string) (*ServiceConfig, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	config, exists := s.configs[serviceName]
 	if !exists {
 		return nil, fmt.Errorf("config not found for service: %s", serviceName)
@@ -0,0 +1,890 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// swagger2Version is the Swagger version converted to OpenAPI 3 on load
+const swagger2Version = "2.0"
+
+// convertedOpenAPIVersion is declared by documents converted from Swagger 2.0
+const convertedOpenAPIVersion = "3.0.3"
+
+// defaultMediaType is used when a Swagger document declares no consumes or produces
+const defaultMediaType = "application/json"
+
+// swaggerRefPrefixes maps Swagger 2.0 local ref prefixes to their OpenAPI 3 locations
+var swaggerRefPrefixes = []struct{ from, to string }{
+	{"#/definitions/", "#/components/schemas/"},
+	{"#/parameters/", "#/components/parameters/"},
+	{"#/responses/", "#/components/responses/"},
+}
+
+// oauth2Flows maps Swagger 2.0 OAuth2 flow names to OpenAPI 3 flow names
+var oauth2Flows = map[string]string{
+	"implicit":    "implicit",
+	"password":    "password",
+	"application": "clientCredentials",
+	"accessCode":  "authorizationCode",
+}
+
+// isSwagger2 reports whether a decoded document declares "swagger": "2.0"
+func isSwagger2(doc map[string]interface{}) bool {
+	version, _ := doc["swagger"].(string)
+	return version == swagger2Version
+}
+
+// swaggerConverter turns a Swagger 2.0 document into an equivalent OpenAPI 3.0 document
+type swaggerConverter struct {
+	consumes   []string           // Document-wide request media types
+	produces   []string           // Document-wide response media types
+	parameters map[string][]field // Global parameters, so refs to body and formData parameters can be inlined
+}
+
+// swaggerParam is an entry of a parameters list with any global $ref followed
+type swaggerParam struct {
+	raw    json.RawMessage // The entry as authored
+	fields []field         // The entry, or the global parameter it references
+	ref    bool
+	name   string
+	in     string
+}
+
+// convertSwagger2 converts a Swagger 2.0 document to OpenAPI 3.0, keeping the authored key order
+// Servers come from host, basePath and schemes; body and formData parameters become
+// requestBody; definitions, parameters, responses and securityDefinitions move under components.
+func convertSwagger2(data []byte) (json.RawMessage, error) {
+	top, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &swaggerConverter{
+		consumes:   stringsField(top, "consumes"),
+		produces:   stringsField(top, "produces"),
+		parameters: make(map[string][]field),
+	}
+
+	var globalParams []field
+	if raw, ok := lookupField(top, "parameters"); ok {
+		if globalParams, err = decodeObject(raw); err != nil {
+			return nil, fmt.Errorf("parameters: %w", err)
+		}
+		for _, p := range globalParams {
+			fields, err := decodeObject(p.value)
+			if err != nil {
+				return nil, fmt.Errorf("parameters/%s: %w", p.key, err)
+			}
+			c.parameters[p.key] = fields
+		}
+	}
+
+	out := []field{{key: "openapi", value: jsonString(convertedOpenAPIVersion)}}
+	if info, ok := lookupField(top, "info"); ok {
+		out = append(out, field{key: "info", value: info})
+	}
+	if servers := swaggerServers(top); servers != nil {
+		out = append(out, field{key: "servers", value: servers})
+	}
+
+	var components []field
+	for _, f := range top {
+		switch f.key {
+		case "swagger", "info", "host", "basePath", "schemes", "consumes", "produces":
+			continue
+
+		case "paths":
+			paths, err := c.convertPaths(f.value)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, field{key: "paths", value: paths})
+
+		case "definitions":
+			components = append(components, field{key: "schemas", value: f.value})
+
+		case "parameters":
+			var params []field
+			for _, p := range globalParams {
+				fields := c.parameters[p.key]
+				// Body and formData parameters are inlined into each requestBody instead
+				if in := stringField(fields, "in"); in == "body" || in == "formData" {
+					continue
+				}
+				param, err := convertParameter(fields)
+				if err != nil {
+					return nil, fmt.Errorf("parameters/%s: %w", p.key, err)
+				}
+				params = append(params, field{key: p.key, value: param})
+			}
+			if len(params) > 0 {
+				obj, err := encodeObject(params)
+				if err != nil {
+					return nil, err
+				}
+				components = append(components, field{key: "parameters", value: obj})
+			}
+
+		case "responses":
+			responses, err := c.convertResponses(f.value, c.produces)
+			if err != nil {
+				return nil, fmt.Errorf("responses: %w", err)
+			}
+			components = append(components, field{key: "responses", value: responses})
+
+		case "securityDefinitions":
+			schemes, err := convertSecurityDefinitions(f.value)
+			if err != nil {
+				return nil, fmt.Errorf("securityDefinitions: %w", err)
+			}
+			components = append(components, field{key: "securitySchemes", value: schemes})
+
+		default:
+			// tags, security, externalDocs and extensions such as x-proxy-config carry over as is
+			out = append(out, f)
+		}
+	}
+
+	if len(components) > 0 {
+		obj, err := encodeObject(components)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, field{key: "components", value: obj})
+	}
+
+	converted, err := encodeObject(out)
+	if err != nil {
+		return nil, err
+	}
+	return rewriteRefs(converted, swaggerRef)
+}
+
+// swaggerServers builds the servers list from host, basePath and schemes
+func swaggerServers(top []field) json.RawMessage {
+	host := stringField(top, "host")
+	basePath := stringField(top, "basePath")
+	if host == "" && basePath == "" {
+		return nil
+	}
+
+	var servers []map[string]string
+	if host == "" {
+		// Relative to wherever the document is served from
+		servers = append(servers, map[string]string{"url": basePath})
+	} else {
+		schemes := stringsField(top, "schemes")
+		if len(schemes) == 0 {
+			schemes = []string{"https"}
+		}
+		for _, scheme := range schemes {
+			servers = append(servers, map[string]string{"url": scheme + "://" + host + basePath})
+		}
+	}
+
+	data, _ := json.Marshal(servers) // Cannot fail for strings
+	return data
+}
+
+// convertPaths converts every operation in the paths object
+func (c *swaggerConverter) convertPaths(raw json.RawMessage) (json.RawMessage, error) {
+	paths, err := decodeObject(raw)
+	if err != nil {
+		return nil, fmt.Errorf("paths: %w", err)
+	}
+
+	for i, p := range paths {
+		if strings.HasPrefix(p.key, "x-") {
+			continue
+		}
+
+		item, err := decodeObject(p.value)
+		if err != nil {
+			return nil, fmt.Errorf("paths/%s: %w", p.key, err)
+		}
+
+		sharedRaw, _ := lookupField(item, "parameters")
+		shared, err := c.resolveParameters(sharedRaw)
+		if err != nil {
+			return nil, fmt.Errorf("paths/%s/parameters: %w", p.key, err)
+		}
+
+		converted := make([]field, 0, len(item))
+		for _, f := range item {
+			switch {
+			case f.key == "parameters":
+				params, err := convertParameterList(shared)
+				if err != nil {
+					return nil, fmt.Errorf("paths/%s/parameters: %w", p.key, err)
+				}
+				if params != nil {
+					converted = append(converted, field{key: "parameters", value: params})
+				}
+			case isOperationMethod(f.key):
+				op, err := c.convertOperation(f.value, shared)
+				if err != nil {
+					return nil, fmt.Errorf("paths/%s/%s: %w", p.key, f.key, err)
+				}
+				converted = append(converted, field{key: f.key, value: op})
+			default:
+				converted = append(converted, f)
+			}
+		}
+
+		if paths[i].value, err = encodeObject(converted); err != nil {
+			return nil, err
+		}
+	}
+
+	return encodeObject(paths)
+}
+
+// convertOperation converts a single operation; shared are the path item's parameters
+func (c *swaggerConverter) convertOperation(raw json.RawMessage, shared []swaggerParam) (json.RawMessage, error) {
+	op, err := decodeObject(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ownRaw, _ := lookupField(op, "parameters")
+	own, err := c.resolveParameters(ownRaw)
+	if err != nil {
+		return nil, fmt.Errorf("parameters: %w", err)
+	}
+
+	consumes := c.consumes
+	if _, ok := lookupField(op, "consumes"); ok {
+		consumes = stringsField(op, "consumes")
+	}
+	produces := c.produces
+	if _, ok := lookupField(op, "produces"); ok {
+		produces = stringsField(op, "produces")
+	}
+
+	requestBody, err := convertRequestBody(mergeParameters(shared, own), consumes)
+	if err != nil {
+		return nil, fmt.Errorf("requestBody: %w", err)
+	}
+
+	out := make([]field, 0, len(op)+1)
+	for _, f := range op {
+		switch f.key {
+		case "consumes", "produces", "schemes":
+			continue
+
+		case "parameters":
+			params, err := convertParameterList(own)
+			if err != nil {
+				return nil, fmt.Errorf("parameters: %w", err)
+			}
+			if params != nil {
+				out = append(out, field{key: "parameters", value: params})
+			}
+			if requestBody != nil {
+				out = append(out, field{key: "requestBody", value: requestBody})
+				requestBody = nil
+			}
+
+		case "responses":
+			if requestBody != nil {
+				out = append(out, field{key: "requestBody", value: requestBody})
+				requestBody = nil
+			}
+			responses, err := c.convertResponses(f.value, produces)
+			if err != nil {
+				return nil, fmt.Errorf("responses: %w", err)
+			}
+			out = append(out, field{key: "responses", value: responses})
+
+		default:
+			out = append(out, f)
+		}
+	}
+	if requestBody != nil {
+		out = append(out, field{key: "requestBody", value: requestBody})
+	}
+
+	return encodeObject(out)
+}
+
+// resolveParameters decodes a parameters list, following refs to global parameters
+func (c *swaggerConverter) resolveParameters(raw json.RawMessage) ([]swaggerParam, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+
+	params := make([]swaggerParam, 0, len(items))
+	for _, item := range items {
+		fields, err := decodeObject(item)
+		if err != nil {
+			return nil, err
+		}
+
+		p := swaggerParam{raw: item, fields: fields}
+		if ref := stringField(fields, "$ref"); ref != "" {
+			p.ref = true
+			if name, ok := strings.CutPrefix(ref, "#/parameters/"); ok {
+				if global, ok := c.parameters[name]; ok {
+					p.fields = global
+				}
+			}
+		}
+		p.name = stringField(p.fields, "name")
+		p.in = stringField(p.fields, "in")
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// mergeParameters combines path item and operation parameters; the operation wins on name and location
+func mergeParameters(shared, own []swaggerParam) []swaggerParam {
+	merged := append([]swaggerParam{}, own...)
+	for _, p := range shared {
+		overridden := false
+		for _, o := range own {
+			if o.name == p.name && o.in == p.in {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// convertParameterList converts the query, header and path parameters of a list
+// Returns nil when nothing is left once body and formData parameters are moved out
+func convertParameterList(params []swaggerParam) (json.RawMessage, error) {
+	var converted []json.RawMessage
+	for _, p := range params {
+		if p.in == "body" || p.in == "formData" {
+			continue
+		}
+		if p.ref {
+			converted = append(converted, p.raw)
+			continue
+		}
+		param, err := convertParameter(p.fields)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, param)
+	}
+	if len(converted) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(converted)
+}
+
+// parameterKeys stay on a converted parameter; everything else describes its schema
+var parameterKeys = map[string]bool{
+	"name":            true,
+	"in":              true,
+	"description":     true,
+	"required":        true,
+	"allowEmptyValue": true,
+}
+
+// convertParameter moves a non-body parameter's type information into a schema
+func convertParameter(fields []field) (json.RawMessage, error) {
+	var out []field
+	for _, f := range fields {
+		if parameterKeys[f.key] || strings.HasPrefix(f.key, "x-") {
+			out = append(out, f)
+		}
+	}
+
+	if stringField(fields, "type") == "array" {
+		if style, explode, ok := collectionStyle(stringField(fields, "in"), stringField(fields, "collectionFormat")); ok {
+			out = append(out, field{key: "style", value: jsonString(style)})
+			out = append(out, field{key: "explode", value: json.RawMessage(strconv.FormatBool(explode))})
+		}
+	}
+
+	schema, err := itemsSchema(fields, false)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, field{key: "schema", value: schema})
+
+	return encodeObject(out)
+}
+
+// collectionStyle maps a Swagger collectionFormat to an OpenAPI 3 style and explode
+// ok is false when the OpenAPI 3 default already matches (or there is no equivalent, as for tsv)
+func collectionStyle(in, format string) (string, bool, bool) {
+	switch format {
+	case "", "csv":
+		// Query arrays default to exploded form in OpenAPI 3; path and header are already simple
+		if in == "query" {
+			return "form", false, true
+		}
+	case "multi":
+		return "form", true, true
+	case "ssv":
+		return "spaceDelimited", false, true
+	case "pipes":
+		return "pipeDelimited", false, true
+	}
+	return "", false, false
+}
+
+// itemsSchema builds a schema from a parameter, header or items object
+// Parameter-only keys and collectionFormat are dropped, and type file becomes a binary string
+func itemsSchema(fields []field, withDescription bool) (json.RawMessage, error) {
+	var schema []field
+	for _, f := range fields {
+		switch {
+		case f.key == "description":
+			if withDescription {
+				schema = append(schema, f)
+			}
+		case parameterKeys[f.key], f.key == "collectionFormat", strings.HasPrefix(f.key, "x-"):
+			continue
+		case f.key == "schema":
+			// Only body parameters carry a schema, and those are not converted here
+			continue
+		case f.key == "type" && stringField(fields, "type") == "file":
+			schema = append(schema, field{key: "type", value: jsonString("string")})
+			schema = append(schema, field{key: "format", value: jsonString("binary")})
+		case f.key == "format" && stringField(fields, "type") == "file":
+			continue
+		case f.key == "items":
+			items, err := decodeObject(f.value)
+			if err != nil {
+				return nil, fmt.Errorf("items: %w", err)
+			}
+			converted, err := itemsSchema(items, true)
+			if err != nil {
+				return nil, err
+			}
+			schema = append(schema, field{key: "items", value: converted})
+		default:
+			schema = append(schema, f)
+		}
+	}
+	return encodeObject(schema)
+}
+
+// convertRequestBody builds a requestBody from body or formData parameters, nil when there are none
+func convertRequestBody(params []swaggerParam, consumes []string) (json.RawMessage, error) {
+	var form []swaggerParam
+	for _, p := range params {
+		switch p.in {
+		case "body":
+			return bodyRequest(p.fields, consumes)
+		case "formData":
+			form = append(form, p)
+		}
+	}
+	if len(form) == 0 {
+		return nil, nil
+	}
+	return formRequest(form, consumes)
+}
+
+// bodyRequest converts a body parameter, offering its schema under every consumed media type
+func bodyRequest(fields []field, consumes []string) (json.RawMessage, error) {
+	if len(consumes) == 0 {
+		consumes = []string{defaultMediaType}
+	}
+
+	schema, ok := lookupField(fields, "schema")
+	if !ok {
+		schema = json.RawMessage(`{}`)
+	}
+	media, err := encodeObject([]field{{key: "schema", value: schema}})
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]field, 0, len(consumes))
+	for _, mediaType := range consumes {
+		content = append(content, field{key: mediaType, value: media})
+	}
+
+	required, _ := lookupField(fields, "required")
+	return requestBodyObject(fields, content, required)
+}
+
+// formRequest converts formData parameters to an object schema
+// File parameters force multipart/form-data; otherwise the first consumed form media type is used
+func formRequest(params []swaggerParam, consumes []string) (json.RawMessage, error) {
+	mediaType := "application/x-www-form-urlencoded"
+	for _, consumed := range consumes {
+		if consumed == "multipart/form-data" || consumed == "application/x-www-form-urlencoded" {
+			mediaType = consumed
+			break
+		}
+	}
+
+	var properties []field
+	var required []string
+	for _, p := range params {
+		if stringField(p.fields, "type") == "file" {
+			mediaType = "multipart/form-data"
+		}
+		schema, err := itemsSchema(p.fields, true)
+		if err != nil {
+			return nil, fmt.Errorf("formData %s: %w", p.name, err)
+		}
+		properties = append(properties, field{key: p.name, value: schema})
+		if boolField(p.fields, "required") {
+			required = append(required, p.name)
+		}
+	}
+
+	props, err := encodeObject(properties)
+	if err != nil {
+		return nil, err
+	}
+	schemaFields := []field{
+		{key: "type", value: jsonString("object")},
+		{key: "properties", value: props},
+	}
+	if len(required) > 0 {
+		list, _ := json.Marshal(required) // Cannot fail for strings
+		schemaFields = append(schemaFields, field{key: "required", value: list})
+	}
+	schema, err := encodeObject(schemaFields)
+	if err != nil {
+		return nil, err
+	}
+	media, err := encodeObject([]field{{key: "schema", value: schema}})
+	if err != nil {
+		return nil, err
+	}
+
+	var requiredBody json.RawMessage
+	if len(required) > 0 {
+		requiredBody = json.RawMessage("true")
+	}
+	return requestBodyObject(nil, []field{{key: mediaType, value: media}}, requiredBody)
+}
+
+// requestBodyObject assembles a requestBody with the parameter's description
+func requestBodyObject(fields []field, content []field, required json.RawMessage) (json.RawMessage, error) {
+	var out []field
+	if description, ok := lookupField(fields, "description"); ok {
+		out = append(out, field{key: "description", value: description})
+	}
+
+	obj, err := encodeObject(content)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, field{key: "content", value: obj})
+
+	if required != nil {
+		out = append(out, field{key: "required", value: required})
+	}
+	return encodeObject(out)
+}
+
+// convertResponses converts every response in a responses object
+func (c *swaggerConverter) convertResponses(raw json.RawMessage, produces []string) (json.RawMessage, error) {
+	responses, err := decodeObject(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range responses {
+		if strings.HasPrefix(r.key, "x-") {
+			continue
+		}
+		if responses[i].value, err = convertResponse(r.value, produces); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.key, err)
+		}
+	}
+	return encodeObject(responses)
+}
+
+// convertResponse moves a response's schema and examples under content
+func convertResponse(raw json.RawMessage, produces []string) (json.RawMessage, error) {
+	fields, err := decodeObject(raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := lookupField(fields, "$ref"); ok {
+		return raw, nil
+	}
+
+	var examples []field
+	if raw, ok := lookupField(fields, "examples"); ok {
+		if examples, err = decodeObject(raw); err != nil {
+			return nil, fmt.Errorf("examples: %w", err)
+		}
+	}
+
+	var out []field
+	for _, f := range fields {
+		switch f.key {
+		case "schema", "examples":
+			continue
+		case "headers":
+			headers, err := convertHeaders(f.value)
+			if err != nil {
+				return nil, fmt.Errorf("headers: %w", err)
+			}
+			out = append(out, field{key: "headers", value: headers})
+		default:
+			out = append(out, f)
+		}
+	}
+
+	schema, ok := lookupField(fields, "schema")
+	if !ok {
+		return encodeObject(out)
+	}
+	if schemaFields, err := decodeObject(schema); err == nil && stringField(schemaFields, "type") == "file" {
+		schema = json.RawMessage(`{"type":"string","format":"binary"}`)
+	}
+
+	if len(produces) == 0 {
+		produces = []string{defaultMediaType}
+	}
+	content := make([]field, 0, len(produces))
+	for _, mediaType := range produces {
+		media := []field{{key: "schema", value: schema}}
+		if example, ok := lookupField(examples, mediaType); ok {
+			media = append(media, field{key: "example", value: example})
+		}
+		obj, err := encodeObject(media)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, field{key: mediaType, value: obj})
+	}
+	obj, err := encodeObject(content)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, field{key: "content", value: obj})
+
+	return encodeObject(out)
+}
+
+// convertHeaders wraps each response header's type information in a schema
+func convertHeaders(raw json.RawMessage) (json.RawMessage, error) {
+	headers, err := decodeObject(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, h := range headers {
+		fields, err := decodeObject(h.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", h.key, err)
+		}
+
+		var out []field
+		for _, f := range fields {
+			if f.key == "description" || strings.HasPrefix(f.key, "x-") {
+				out = append(out, f)
+			}
+		}
+		schema, err := itemsSchema(fields, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", h.key, err)
+		}
+		out = append(out, field{key: "schema", value: schema})
+
+		if headers[i].value, err = encodeObject(out); err != nil {
+			return nil, err
+		}
+	}
+	return encodeObject(headers)
+}
+
+// convertSecurityDefinitions converts securityDefinitions to securitySchemes
+func convertSecurityDefinitions(raw json.RawMessage) (json.RawMessage, error) {
+	defs, err := decodeObject(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, d := range defs {
+		fields, err := decodeObject(d.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", d.key, err)
+		}
+
+		var out []field
+		switch stringField(fields, "type") {
+		case "basic":
+			out = []field{
+				{key: "type", value: jsonString("http")},
+				{key: "scheme", value: jsonString("basic")},
+			}
+		case "apiKey":
+			out = []field{{key: "type", value: jsonString("apiKey")}}
+			out = appendFields(out, fields, "name", "in")
+		case "oauth2":
+			flowName, ok := oauth2Flows[stringField(fields, "flow")]
+			if !ok {
+				return nil, fmt.Errorf("%s: unknown oauth2 flow %q", d.key, stringField(fields, "flow"))
+			}
+			flow := appendFields(nil, fields, "authorizationUrl", "tokenUrl")
+			scopes, ok := lookupField(fields, "scopes")
+			if !ok {
+				scopes = json.RawMessage(`{}`)
+			}
+			flow = append(flow, field{key: "scopes", value: scopes})
+
+			flowObj, err := encodeObject(flow)
+			if err != nil {
+				return nil, err
+			}
+			flows, err := encodeObject([]field{{key: flowName, value: flowObj}})
+			if err != nil {
+				return nil, err
+			}
+			out = []field{
+				{key: "type", value: jsonString("oauth2")},
+				{key: "flows", value: flows},
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown security type %q", d.key, stringField(fields, "type"))
+		}
+
+		out = appendFields(out, fields, "description")
+		for _, f := range fields {
+			if strings.HasPrefix(f.key, "x-") {
+				out = append(out, f)
+			}
+		}
+
+		if defs[i].value, err = encodeObject(out); err != nil {
+			return nil, err
+		}
+	}
+	return encodeObject(defs)
+}
+
+// swaggerRef points a Swagger 2.0 local ref at the matching OpenAPI 3 component
+func swaggerRef(ref string) string {
+	for _, prefix := range swaggerRefPrefixes {
+		if rest, ok := strings.CutPrefix(ref, prefix.from); ok {
+			return prefix.to + rest
+		}
+	}
+	return ref
+}
+
+// rewriteRefs rebuilds a JSON value with every $ref string passed through fn
+func rewriteRefs(raw json.RawMessage, fn func(string) string) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	switch raw[0] {
+	case '{':
+		fields, err := decodeObject(raw)
+		if err != nil {
+			return nil, err
+		}
+		for i, f := range fields {
+			var ref string
+			if f.key == "$ref" && json.Unmarshal(f.value, &ref) == nil {
+				fields[i].value = jsonString(fn(ref))
+				continue
+			}
+			if fields[i].value, err = rewriteRefs(f.value, fn); err != nil {
+				return nil, err
+			}
+		}
+		return encodeObject(fields)
+
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		for i, item := range items {
+			converted, err := rewriteRefs(item, fn)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = converted
+		}
+		return json.Marshal(items)
+
+	default:
+		return raw, nil
+	}
+}
+
+// isOperationMethod reports whether a path item key holds an operation
+func isOperationMethod(key string) bool {
+	for _, method := range operationMethods {
+		if key == method {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupField returns the value of the first member with the given key
+func lookupField(fields []field, key string) (json.RawMessage, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f.value, true
+		}
+	}
+	return nil, false
+}
+
+// appendFields copies the named members, in the given order, when present
+func appendFields(out, fields []field, keys ...string) []field {
+	for _, key := range keys {
+		if value, ok := lookupField(fields, key); ok {
+			out = append(out, field{key: key, value: value})
+		}
+	}
+	return out
+}
+
+// stringField returns a member's string value, or "" when absent or not a string
+func stringField(fields []field, key string) string {
+	var s string
+	if raw, ok := lookupField(fields, key); ok {
+		_ = json.Unmarshal(raw, &s)
+	}
+	return s
+}
+
+// stringsField returns a member's string list value, or nil when absent or not a list
+func stringsField(fields []field, key string) []string {
+	var list []string
+	if raw, ok := lookupField(fields, key); ok {
+		_ = json.Unmarshal(raw, &list)
+	}
+	return list
+}
+
+// boolField returns a member's boolean value, or false when absent or not a boolean
+func boolField(fields []field, key string) bool {
+	var b bool
+	if raw, ok := lookupField(fields, key); ok {
+		_ = json.Unmarshal(raw, &b)
+	}
+	return b
+}
+
+// jsonString encodes s as a JSON string
+func jsonString(s string) json.RawMessage {
+	data, _ := json.Marshal(s) // Cannot fail for strings
+	return data
+}
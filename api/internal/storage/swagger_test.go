@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const petstoreSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Petstore", "version": "1.0"},
+  "host": "pets.example.com",
+  "basePath": "/v1",
+  "schemes": ["https", "http"],
+  "consumes": ["application/json"],
+  "produces": ["application/json"],
+  "x-proxy-config": {"baseURL": "http://pets.internal"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "tags", "in": "query", "type": "array", "items": {"type": "string"}},
+          {"$ref": "#/parameters/limit"}
+        ],
+        "responses": {
+          "200": {
+            "description": "ok",
+            "headers": {"X-Next": {"type": "string", "description": "next page"}},
+            "schema": {"type": "array", "items": {"$ref": "#/definitions/Pet"}},
+            "examples": {"application/json": [{"name": "Rex"}]}
+          },
+          "default": {"$ref": "#/responses/Error"}
+        }
+      },
+      "post": {
+        "operationId": "createPet",
+        "parameters": [{"$ref": "#/parameters/petBody"}],
+        "responses": {"201": {"description": "created"}}
+      }
+    },
+    "/pets/{petId}/photo": {
+      "parameters": [{"name": "petId", "in": "path", "required": true, "type": "integer", "format": "int64"}],
+      "post": {
+        "consumes": ["multipart/form-data"],
+        "parameters": [
+          {"name": "caption", "in": "formData", "type": "string"},
+          {"name": "file", "in": "formData", "type": "file", "required": true}
+        ],
+        "responses": {"204": {"description": "uploaded"}}
+      }
+    }
+  },
+  "parameters": {
+    "limit": {"name": "limit", "in": "query", "type": "integer", "default": 20},
+    "petBody": {"name": "pet", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Pet"}}
+  },
+  "responses": {
+    "Error": {"description": "error", "schema": {"$ref": "#/definitions/Error"}}
+  },
+  "definitions": {
+    "Pet": {"type": "object", "properties": {"name": {"type": "string"}}},
+    "Error": {"type": "object"}
+  },
+  "securityDefinitions": {
+    "basicAuth": {"type": "basic"},
+    "oauth": {"type": "oauth2", "flow": "application", "tokenUrl": "https://auth.example.com/token", "scopes": {"read": "read pets"}}
+  }
+}`
+
+func TestConvertSwagger2(t *testing.T) {
+	converted, err := convertSwagger2([]byte(petstoreSwagger))
+	if err != nil {
+		t.Fatalf("convertSwagger2() failed: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(converted, &doc); err != nil {
+		t.Fatalf("failed to unmarshal converted spec: %v", err)
+	}
+
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{"/openapi", `"3.0.3"`},
+		{"/servers", `[{"url":"https://pets.example.com/v1"},{"url":"http://pets.example.com/v1"}]`},
+		{"/paths/~1pets/get/parameters/0", `{"explode":false,"in":"query","name":"tags","schema":{"items":{"type":"string"},"type":"array"},"style":"form"}`},
+		{"/paths/~1pets/get/parameters/1", `{"$ref":"#/components/parameters/limit"}`},
+		{"/paths/~1pets/get/responses/200/headers/X-Next", `{"description":"next page","schema":{"type":"string"}}`},
+		{"/paths/~1pets/get/responses/200/content/application~1json/schema/items", `{"$ref":"#/components/schemas/Pet"}`},
+		{"/paths/~1pets/get/responses/200/content/application~1json/example", `[{"name":"Rex"}]`},
+		{"/paths/~1pets/get/responses/default", `{"$ref":"#/components/responses/Error"}`},
+		{"/paths/~1pets/post/requestBody", `{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/Pet"}}},"required":true}`},
+		{"/paths/~1pets~1{petId}~1photo/parameters/0/schema", `{"format":"int64","type":"integer"}`},
+		{"/paths/~1pets~1{petId}~1photo/post/requestBody/content/multipart~1form-data/schema", `{"properties":{"caption":{"type":"string"},"file":{"format":"binary","type":"string"}},"required":["file"],"type":"object"}`},
+		{"/components/parameters/limit", `{"in":"query","name":"limit","schema":{"default":20,"type":"integer"}}`},
+		{"/components/responses/Error/content/application~1json/schema", `{"$ref":"#/components/schemas/Error"}`},
+		{"/components/schemas/Pet/type", `"object"`},
+		{"/components/securitySchemes/basicAuth", `{"scheme":"basic","type":"http"}`},
+		{"/components/securitySchemes/oauth/flows/clientCredentials/tokenUrl", `"https://auth.example.com/token"`},
+		{"/x-proxy-config/baseURL", `"http://pets.internal"`},
+	}
+
+	for _, tt := range tests {
+		got, ok := resolvePointer(doc, tt.pointer)
+		if !ok {
+			t.Errorf("%s: not found in %s", tt.pointer, converted)
+			continue
+		}
+		var want interface{}
+		if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+			t.Fatalf("bad expectation for %s: %v", tt.pointer, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			gotJSON, _ := json.Marshal(got)
+			t.Errorf("%s: expected %s, got %s", tt.pointer, tt.want, gotJSON)
+		}
+	}
+
+	for _, key := range []string{"swagger", "host", "basePath", "schemes", "consumes", "produces", "definitions", "parameters", "responses", "securityDefinitions"} {
+		if _, exists := doc[key]; exists {
+			t.Errorf("expected %s to be removed", key)
+		}
+	}
+	if _, exists := doc["components"].(map[string]interface{})["parameters"].(map[string]interface{})["petBody"]; exists {
+		t.Error("expected body parameter to be inlined, not kept as a component")
+	}
+
+	// Converted documents pass OpenAPI 3 validation
+	if diags := validateSpec(doc); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %+v", diags)
+	}
+
+	// Paths keep their authored order
+	if strings.Index(string(converted), `"/pets"`) > strings.Index(string(converted), `"/pets/{petId}/photo"`) {
+		t.Error("expected paths to keep their order")
+	}
+}
+
+func TestFileSpecStore_Swagger2(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "petstore.json"), []byte(petstoreSwagger), 0644); err != nil {
+		t.Fatalf("failed to write spec: %v", err)
+	}
+
+	store, err := NewFileSpecStore(tempDir, WithValidationMode(ValidationStrict))
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	spec, _ := store.Get("petstore")
+	if !strings.HasPrefix(string(spec), `{"openapi":"3.0.3"`) {
+		t.Errorf("expected converted spec, got %.60s", spec)
+	}
+
+	original, err := store.GetOriginal("petstore")
+	if err != nil {
+		t.Fatalf("GetOriginal() failed: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(original, &doc); err != nil {
+		t.Fatalf("failed to unmarshal original: %v", err)
+	}
+	if doc["swagger"] != "2.0" {
+		t.Errorf("expected original swagger document, got %v", doc["swagger"])
+	}
+	if !strings.Contains(string(original), `"x-proxy-config":{"baseURL":"http://pets.internal"}`) {
+		t.Errorf("expected redacted proxy config in original, got %s", original)
+	}
+
+	config, err := store.GetConfig("petstore")
+	if err != nil || config.BaseURL != "http://pets.internal" {
+		t.Errorf("expected proxy config from converted spec, got %+v (%v)", config, err)
+	}
+
+	// OpenAPI 3 specs have no separate original
+	writeSpecFile(t, tempDir, "modern.json", map[string]interface{}{
+		"openapi": "3.0.0",
+		"info":    map[string]interface{}{"title": "Modern", "version": "1"},
+		"paths":   map[string]interface{}{},
+	})
+	if err := store.Reload(); err != nil {
+		t.Fatalf("Reload() failed: %v", err)
+	}
+	spec, _ = store.Get("modern")
+	original, _ = store.GetOriginal("modern")
+	if string(spec) != string(original) {
+		t.Errorf("expected original to match spec for OpenAPI 3, got %s vs %s", original, spec)
+	}
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timeouts returns the service's connect and response timeouts
+// Unset or invalid values are zero, leaving the choice to the proxy's defaults.
+func (c *ServiceConfig) Timeouts() (connect, response time.Duration) {
+	connect, _ = parseTimeout(c.ConnectTimeout)
+	response, _ = parseTimeout(c.ResponseTimeout)
+	return connect, response
+}
+
+// parseTimeout parses a positive Go duration; an empty string is zero
+func parseTimeout(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %v", raw, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("timeout must be positive, got %q", raw)
+	}
+	return d, nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// TLSVersions lists the minVersion values x-proxy-config accepts
+var TLSVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
+// TLSConfig describes how the proxy connects to a service over TLS
+// File paths may use ${env:...} placeholders and are relative to the spec file's directory.
+type TLSConfig struct {
+	CertFile           string `json:"certFile,omitempty"`           // PEM client certificate for mTLS, requires KeyFile
+	KeyFile            string `json:"keyFile,omitempty"`            // PEM private key for CertFile
+	CAFile             string `json:"caFile,omitempty"`             // PEM bundle trusted in addition to the system roots
+	MinVersion         string `json:"minVersion,omitempty"`         // One of TLSVersions; defaults to 1.2
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // Local development only
+}
+
+// PublicTLS is the redacted view of a TLSConfig, without file locations
+type PublicTLS struct {
+	ClientCertificate  bool   `json:"clientCertificate,omitempty"`
+	CustomCA           bool   `json:"customCA,omitempty"`
+	MinVersion         string `json:"minVersion,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// publicTLS summarizes a TLS config, or returns nil when there is none
+func publicTLS(config *TLSConfig) *PublicTLS {
+	if config == nil {
+		return nil
+	}
+	return &PublicTLS{
+		ClientCertificate:  config.CertFile != "",
+		CustomCA:           config.CAFile != "",
+		MinVersion:         config.MinVersion,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+}
+
+// resolveTLSPaths resolves placeholders in a TLS config's file paths in place and makes
+// relative paths absolute against dir
+func resolveTLSPaths(config *TLSConfig, dir string) error {
+	for _, path := range []*string{&config.CertFile, &config.KeyFile, &config.CAFile} {
+		if *path == "" {
+			continue
+		}
+		resolved, err := resolveSecrets(*path)
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		if !filepath.IsAbs(resolved) {
+			resolved = filepath.Join(dir, filepath.FromSlash(resolved))
+		}
+		*path = resolved
+	}
+	return nil
+}
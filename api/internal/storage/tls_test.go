@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSpecStore_TLSPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("PLAYGROUND_TEST_CERTS", "/etc/playground/certs")
+
+	writeFiles(t, tempDir, map[string]string{
+		"billing/openapi.json": `{"openapi":"3.0.0","info":{"title":"Billing","version":"1"},"paths":{},
+			"x-proxy-config":{"baseURL":"https://billing.internal","tls":{
+				"certFile":"certs/client.pem","keyFile":"${env:PLAYGROUND_TEST_CERTS}/client-key.pem",
+				"caFile":"/etc/ssl/internal-ca.pem","minVersion":"1.3"}}}`,
+	})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	config, err := store.GetConfig("billing")
+	if err != nil {
+		t.Fatalf("GetConfig() failed: %v", err)
+	}
+	if config.TLS == nil {
+		t.Fatal("expected a TLS config")
+	}
+	if want := filepath.Join(tempDir, "billing", "certs", "client.pem"); config.TLS.CertFile != want {
+		t.Errorf("expected cert path relative to the spec, got %s", config.TLS.CertFile)
+	}
+	if config.TLS.KeyFile != "/etc/playground/certs/client-key.pem" {
+		t.Errorf("expected placeholder to be resolved, got %s", config.TLS.KeyFile)
+	}
+	if config.TLS.CAFile != "/etc/ssl/internal-ca.pem" {
+		t.Errorf("expected absolute CA path to be kept, got %s", config.TLS.CAFile)
+	}
+
+	spec, err := store.Get("billing")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if strings.Contains(string(spec), "client.pem") {
+		t.Error("expected TLS file locations to be redacted from the served spec")
+	}
+	var doc struct {
+		ProxyConfig PublicProxyConfig `json:"x-proxy-config"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatalf("failed to decode spec: %v", err)
+	}
+	if tls := doc.ProxyConfig.TLS; tls == nil || !tls.ClientCertificate || !tls.CustomCA || tls.MinVersion != "1.3" {
+		t.Errorf("expected a TLS summary, got %+v", tls)
+	}
+}
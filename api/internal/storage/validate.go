@@ -0,0 +1,618 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationMode controls what happens to specs with error diagnostics
+type ValidationMode string
+
+const (
+	// ValidationWarn loads invalid specs and reports their diagnostics
+	ValidationWarn ValidationMode = "warn"
+
+	// ValidationStrict rejects specs that have error diagnostics
+	ValidationStrict ValidationMode = "strict"
+)
+
+// Diagnostic is a single validation finding for a spec
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Pointer  string `json:"pointer"` // JSON pointer to the offending node, e.g. /paths/~1pets/get
+	Message  string `json:"message"`
+}
+
+// ValidationError is returned when a spec is rejected for error diagnostics
+type ValidationError struct {
+	File        string
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	errs := errorDiagnostics(e.Diagnostics)
+	if len(errs) == 0 {
+		return fmt.Sprintf("spec file %s failed validation", e.File)
+	}
+	return fmt.Sprintf("spec file %s failed validation: %s: %s (%d errors)", e.File, errs[0].Pointer, errs[0].Message, len(errs))
+}
+
+// Unwrap lets callers match ValidationError with errors.Is(err, ErrInvalidSpec)
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidSpec
+}
+
+// errorDiagnostics filters diagnostics down to errors
+func errorDiagnostics(diags []Diagnostic) []Diagnostic {
+	var errs []Diagnostic
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	return errs
+}
+
+var (
+	// openAPIVersionPattern matches the OpenAPI versions the UI understands
+	openAPIVersionPattern = regexp.MustCompile(`^3\.[01]\.\d+$`)
+
+	// headerNamePattern matches RFC 7230 header field names
+	headerNamePattern = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+	// redirectHostPattern matches redirectHosts entries: a host name, *.domain or *
+	redirectHostPattern = regexp.MustCompile(`^(\*|(\*\.)?[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*)$`)
+
+	// templateParamPattern matches {name} in path templates
+	templateParamPattern = regexp.MustCompile(`\{([^}/]+)\}`)
+)
+
+// operationMethods are the path item keys that hold operations
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// validateSpec checks OpenAPI 3.0/3.1 structure, local $refs and x-proxy-config (including environments)
+func validateSpec(doc map[string]interface{}) []Diagnostic {
+	v := &validator{doc: doc}
+
+	v.checkVersion()
+	v.checkInfo()
+	v.checkPaths()
+	v.checkRefs(doc, "")
+	v.checkProxyConfig()
+
+	return v.diags
+}
+
+// validator accumulates diagnostics for a single document
+type validator struct {
+	doc   map[string]interface{}
+	is31  bool
+	diags []Diagnostic
+}
+
+func (v *validator) errorf(pointer, format string, args ...interface{}) {
+	v.diags = append(v.diags, Diagnostic{Severity: SeverityError, Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(pointer, format string, args ...interface{}) {
+	v.diags = append(v.diags, Diagnostic{Severity: SeverityWarning, Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) checkVersion() {
+	raw, exists := v.doc["openapi"]
+	if !exists {
+		v.errorf("/openapi", "missing required field openapi")
+		return
+	}
+
+	version, ok := raw.(string)
+	if !ok {
+		v.errorf("/openapi", "openapi must be a string")
+		return
+	}
+	if !openAPIVersionPattern.MatchString(version) {
+		v.errorf("/openapi", "unsupported OpenAPI version %q, expected 3.0.x or 3.1.x", version)
+		return
+	}
+
+	v.is31 = strings.HasPrefix(version, "3.1.")
+}
+
+func (v *validator) checkInfo() {
+	info, ok := v.doc["info"].(map[string]interface{})
+	if !ok {
+		v.errorf("/info", "missing required object info")
+		return
+	}
+
+	for _, field := range []string{"title", "version"} {
+		if s, ok := info[field].(string); !ok || s == "" {
+			v.errorf("/info/"+field, "missing required field info.%s", field)
+		}
+	}
+}
+
+func (v *validator) checkPaths() {
+	raw, exists := v.doc["paths"]
+	if !exists {
+		// 3.1 allows documents that only carry webhooks or components
+		_, hasWebhooks := v.doc["webhooks"]
+		_, hasComponents := v.doc["components"]
+		if !v.is31 || (!hasWebhooks && !hasComponents) {
+			v.errorf("/paths", "missing required object paths")
+		}
+		return
+	}
+
+	paths, ok := raw.(map[string]interface{})
+	if !ok {
+		v.errorf("/paths", "paths must be an object")
+		return
+	}
+
+	operationIDs := make(map[string]string)
+
+	for _, path := range sortedKeys(paths) {
+		pathPtr := "/paths/" + escapePointer(path)
+
+		if !strings.HasPrefix(path, "/") {
+			v.errorf(pathPtr, "path %q must start with /", path)
+		}
+
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			v.errorf(pathPtr, "path item must be an object")
+			continue
+		}
+
+		for _, method := range operationMethods {
+			raw, exists := item[method]
+			if !exists {
+				continue
+			}
+			opPtr := pathPtr + "/" + method
+
+			op, ok := raw.(map[string]interface{})
+			if !ok {
+				v.errorf(opPtr, "operation must be an object")
+				continue
+			}
+
+			if _, exists := op["responses"]; !exists && !v.is31 {
+				v.errorf(opPtr+"/responses", "missing required object responses")
+			}
+
+			if id, ok := op["operationId"].(string); ok {
+				if other, dup := operationIDs[id]; dup {
+					v.errorf(opPtr+"/operationId", "duplicate operationId %q, also used at %s", id, other)
+				} else {
+					operationIDs[id] = opPtr
+				}
+			}
+
+			v.checkPathParams(path, item, op, opPtr)
+		}
+	}
+}
+
+// checkPathParams warns when a templated path segment has no matching path parameter
+func (v *validator) checkPathParams(path string, item, op map[string]interface{}, opPtr string) {
+	declared := make(map[string]bool)
+	for _, params := range []interface{}{item["parameters"], op["parameters"]} {
+		list, _ := params.([]interface{})
+		for _, p := range list {
+			param, ok := v.deref(p).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if param["in"] == "path" {
+				if name, ok := param["name"].(string); ok {
+					declared[name] = true
+				}
+			}
+		}
+	}
+
+	for _, m := range templateParamPattern.FindAllStringSubmatch(path, -1) {
+		if !declared[m[1]] {
+			v.warnf(opPtr+"/parameters", "path parameter %q is not declared", m[1])
+		}
+	}
+}
+
+// checkRefs walks the document and reports $refs that do not resolve
+func (v *validator) checkRefs(node interface{}, pointer string) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok {
+			switch {
+			case strings.HasPrefix(ref, "#"):
+				if _, ok := resolvePointer(v.doc, ref[1:]); !ok {
+					v.errorf(pointer+"/$ref", "broken $ref %q", ref)
+				}
+			default:
+				v.warnf(pointer+"/$ref", "external $ref %q is not resolved", ref)
+			}
+		}
+		for _, key := range sortedKeys(n) {
+			v.checkRefs(n[key], pointer+"/"+escapePointer(key))
+		}
+	case []interface{}:
+		for i, item := range n {
+			v.checkRefs(item, pointer+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func (v *validator) checkProxyConfig() {
+	raw, exists := v.doc[proxyConfigKey]
+	if !exists {
+		return
+	}
+	ptr := "/" + proxyConfigKey
+
+	cfg, ok := raw.(map[string]interface{})
+	if !ok {
+		v.errorf(ptr, "x-proxy-config must be an object")
+		return
+	}
+
+	// With environments declared the service-wide baseURL is optional
+	environments, hasEnvironments := cfg["environments"]
+	if baseURL, exists := cfg["baseURL"]; exists || !hasEnvironments {
+		v.checkBaseURL(ptr+"/baseURL", baseURL)
+	}
+	v.checkAuthHeaders(ptr+"/authHeaders", cfg["authHeaders"])
+	for _, key := range []string{"connectTimeout", "responseTimeout"} {
+		if raw, exists := cfg[key]; exists {
+			v.checkTimeout(ptr+"/"+key, raw)
+		}
+	}
+	if raw, exists := cfg["retry"]; exists {
+		v.checkRetry(ptr+"/retry", raw)
+	}
+	if raw, exists := cfg["tls"]; exists {
+		v.checkTLS(ptr+"/tls", raw)
+	}
+	if raw, exists := cfg["redirectHosts"]; exists {
+		v.checkRedirectHosts(ptr+"/redirectHosts", raw)
+	}
+	if raw, exists := cfg["oauth2"]; exists {
+		v.checkOAuth2(ptr+"/oauth2", raw)
+	}
+	if raw, exists := cfg["signing"]; exists {
+		v.checkSigning(ptr+"/signing", raw)
+	}
+
+	if !hasEnvironments {
+		if _, exists := cfg["defaultEnvironment"]; exists {
+			v.errorf(ptr+"/defaultEnvironment", "defaultEnvironment requires environments")
+		}
+		return
+	}
+
+	envs, ok := environments.(map[string]interface{})
+	if !ok {
+		v.errorf(ptr+"/environments", "environments must be an object")
+		return
+	}
+	for _, name := range sortedKeys(envs) {
+		envPtr := ptr + "/environments/" + escapePointer(name)
+		env, ok := envs[name].(map[string]interface{})
+		if !ok {
+			v.errorf(envPtr, "environment must be an object")
+			continue
+		}
+		v.checkBaseURL(envPtr+"/baseURL", env["baseURL"])
+		v.checkAuthHeaders(envPtr+"/authHeaders", env["authHeaders"])
+	}
+
+	if raw, exists := cfg["defaultEnvironment"]; exists {
+		name, _ := raw.(string)
+		if _, ok := envs[name]; !ok {
+			v.errorf(ptr+"/defaultEnvironment", "defaultEnvironment %q is not a declared environment", name)
+		}
+	}
+}
+
+func (v *validator) checkBaseURL(pointer string, raw interface{}) {
+	baseURL, _ := raw.(string)
+	if err := validateBaseURL(baseURL); err != nil {
+		v.errorf(pointer, "%v", err)
+	}
+}
+
+func (v *validator) checkAuthHeaders(pointer string, raw interface{}) {
+	headers, _ := raw.(map[string]interface{})
+	for _, name := range sortedKeys(headers) {
+		if !headerNamePattern.MatchString(name) {
+			v.errorf(pointer+"/"+escapePointer(name), "invalid header name %q", name)
+		}
+		if _, ok := headers[name].(string); !ok {
+			v.errorf(pointer+"/"+escapePointer(name), "header value must be a string")
+		}
+	}
+}
+
+func (v *validator) checkTimeout(pointer string, raw interface{}) {
+	value, ok := raw.(string)
+	if !ok {
+		v.errorf(pointer, "timeout must be a duration string, e.g. 5s")
+		return
+	}
+	if _, err := parseTimeout(value); err != nil {
+		v.errorf(pointer, "%v", err)
+	}
+}
+
+// maxRetryAttempts keeps a retry policy from hammering a struggling backend
+const maxRetryAttempts = 10
+
+func (v *validator) checkRetry(pointer string, raw interface{}) {
+	retry, ok := raw.(map[string]interface{})
+	if !ok {
+		v.errorf(pointer, "retry must be an object")
+		return
+	}
+
+	attempts, ok := retry["maxAttempts"].(float64)
+	if !ok || attempts != float64(int(attempts)) || attempts < 1 || attempts > maxRetryAttempts {
+		v.errorf(pointer+"/maxAttempts", "maxAttempts must be an integer from 1 to %d", maxRetryAttempts)
+	}
+	for _, key := range []string{"backoff", "maxBackoff"} {
+		if raw, exists := retry[key]; exists {
+			v.checkTimeout(pointer+"/"+key, raw)
+		}
+	}
+
+	if raw, exists := retry["retryOn"]; exists {
+		codes, ok := raw.([]interface{})
+		if !ok {
+			v.errorf(pointer+"/retryOn", "retryOn must be an array of status codes")
+			return
+		}
+		for i, item := range codes {
+			code, ok := item.(float64)
+			if !ok || code != float64(int(code)) || code < 100 || code > 599 {
+				v.errorf(pointer+"/retryOn/"+strconv.Itoa(i), "retryOn entries must be HTTP status codes")
+			}
+		}
+	}
+}
+
+func (v *validator) checkTLS(pointer string, raw interface{}) {
+	config, ok := raw.(map[string]interface{})
+	if !ok {
+		v.errorf(pointer, "tls must be an object")
+		return
+	}
+
+	for _, key := range []string{"certFile", "keyFile", "caFile"} {
+		if raw, exists := config[key]; exists {
+			if path, ok := raw.(string); !ok || path == "" {
+				v.errorf(pointer+"/"+key, "%s must be a non-empty path", key)
+			}
+		}
+	}
+	_, hasCert := config["certFile"]
+	_, hasKey := config["keyFile"]
+	if hasCert != hasKey {
+		v.errorf(pointer, "certFile and keyFile must be set together")
+	}
+
+	if raw, exists := config["minVersion"]; exists {
+		version, _ := raw.(string)
+		if !containsString(TLSVersions, version) {
+			v.errorf(pointer+"/minVersion", "minVersion must be one of %s", strings.Join(TLSVersions, ", "))
+		}
+	}
+
+	if config["insecureSkipVerify"] == true {
+		v.warnf(pointer+"/insecureSkipVerify", "certificate verification is disabled; use only for local development")
+	}
+}
+
+func (v *validator) checkRedirectHosts(pointer string, raw interface{}) {
+	hosts, ok := raw.([]interface{})
+	if !ok {
+		v.errorf(pointer, "redirectHosts must be an array of host names")
+		return
+	}
+	for i, item := range hosts {
+		host, ok := item.(string)
+		if !ok || !redirectHostPattern.MatchString(host) {
+			v.errorf(pointer+"/"+strconv.Itoa(i), "redirectHosts entries must be a host name, *.domain or *")
+		}
+	}
+}
+
+func (v *validator) checkOAuth2(pointer string, raw interface{}) {
+	config, ok := raw.(map[string]interface{})
+	if !ok {
+		v.errorf(pointer, "oauth2 must be an object")
+		return
+	}
+
+	tokenURL, _ := config["tokenURL"].(string)
+	if err := validateHTTPURL("tokenURL", tokenURL); err != nil {
+		v.errorf(pointer+"/tokenURL", "%v", err)
+	}
+	if id, _ := config["clientId"].(string); id == "" {
+		v.errorf(pointer+"/clientId", "clientId is required")
+	}
+	if raw, exists := config["scopes"]; exists {
+		scopes, ok := raw.([]interface{})
+		if !ok {
+			v.errorf(pointer+"/scopes", "scopes must be an array of strings")
+		}
+		for i, scope := range scopes {
+			if s, ok := scope.(string); !ok || s == "" || strings.ContainsAny(s, " \"") {
+				v.errorf(pointer+"/scopes/"+strconv.Itoa(i), "scopes entries must be non-empty strings without spaces")
+			}
+		}
+	}
+	if raw, exists := config["clientAuth"]; exists && raw != ClientAuthBasic && raw != ClientAuthBody {
+		v.errorf(pointer+"/clientAuth", "clientAuth must be %s or %s", ClientAuthBasic, ClientAuthBody)
+	}
+}
+
+// signingRequiredFields lists the fields each built-in signer cannot work without
+var signingRequiredFields = map[string][]string{
+	SignerAWSSigV4: {"accessKeyId", "secretAccessKey", "region", "service"},
+	SignerHMAC:     {"secret"},
+	SignerBasic:    {"username"},
+}
+
+func (v *validator) checkSigning(pointer string, raw interface{}) {
+	config, ok := raw.(map[string]interface{})
+	if !ok {
+		v.errorf(pointer, "signing must be an object")
+		return
+	}
+
+	signerType, _ := config["type"].(string)
+	if signerType == "" {
+		v.errorf(pointer+"/type", "type is required")
+		return
+	}
+	required, builtin := signingRequiredFields[signerType]
+	if !builtin {
+		v.warnf(pointer+"/type", "%q is not a built-in signer (%s, %s, %s); it must be registered with the proxy",
+			signerType, SignerAWSSigV4, SignerHMAC, SignerBasic)
+		return
+	}
+	for _, key := range required {
+		if value, _ := config[key].(string); value == "" {
+			v.errorf(pointer+"/"+key, "%s is required for %s signing", key, signerType)
+		}
+	}
+
+	if signerType == SignerHMAC {
+		if raw, exists := config["header"]; exists {
+			if name, _ := raw.(string); !headerNamePattern.MatchString(name) {
+				v.errorf(pointer+"/header", "invalid header name %q", name)
+			}
+		}
+		if raw, exists := config["timestampHeader"]; exists {
+			if name, _ := raw.(string); !headerNamePattern.MatchString(name) {
+				v.errorf(pointer+"/timestampHeader", "invalid header name %q", name)
+			}
+		}
+		if raw, exists := config["encoding"]; exists && raw != "hex" && raw != "base64" {
+			v.errorf(pointer+"/encoding", "encoding must be hex or base64")
+		}
+	}
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBaseURL requires an absolute http(s) URL with a host, or ws(s) for WebSocket services
+func validateBaseURL(raw string) error {
+	return validateURL("baseURL", raw, "http", "https", "ws", "wss")
+}
+
+// validateHTTPURL requires the named field to be an absolute http(s) URL with a host
+func validateHTTPURL(name, raw string) error {
+	return validateURL(name, raw, "http", "https")
+}
+
+// validateURL requires the named field to be an absolute URL with a host and one of the schemes
+func validateURL(name, raw string, schemes ...string) error {
+	if raw == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid URL: %v", name, err)
+	}
+	if !containsString(schemes, u.Scheme) {
+		return fmt.Errorf("%s must use %s, got %q", name, strings.Join(schemes, " or "), redactURL(raw))
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%s must be absolute, got %q", name, redactURL(raw))
+	}
+	return nil
+}
+
+// deref follows a local $ref, returning the node unchanged if it is not a reference
+func (v *validator) deref(node interface{}) interface{} {
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return node
+	}
+	ref, ok := obj["$ref"].(string)
+	if !ok || !strings.HasPrefix(ref, "#") {
+		return node
+	}
+	target, _ := resolvePointer(v.doc, ref[1:])
+	return target
+}
+
+// resolvePointer looks up an RFC 6901 JSON pointer in a decoded document
+func resolvePointer(doc interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return doc, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	node := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+		switch n := node.(type) {
+		case map[string]interface{}:
+			next, exists := n[token]
+			if !exists {
+				return nil, false
+			}
+			node = next
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, false
+			}
+			node = n[i]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// escapePointer escapes a key for use as a JSON pointer token
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []Diagnostic // Expected severity and pointer, in order
+	}{
+		{
+			name: "valid 3.0",
+			doc: `{"openapi":"3.0.3","info":{"title":"Pets","version":"1"},
+				"paths":{"/pets/{id}":{"parameters":[{"$ref":"#/components/parameters/id"}],
+				"get":{"responses":{"200":{"$ref":"#/components/responses/Pet"}}}}},
+				"components":{"parameters":{"id":{"name":"id","in":"path","required":true}},
+				"responses":{"Pet":{"description":"ok"}}},
+				"x-proxy-config":{"baseURL":"https://pets.example.com","authHeaders":{"X-Api-Key":"k"}}}`,
+		},
+		{
+			name: "valid websocket base URL",
+			doc: `{"openapi":"3.0.0","info":{"title":"Chat","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"wss://chat.example.com/socket"}}`,
+		},
+		{
+			name: "valid 3.1 without paths",
+			doc:  `{"openapi":"3.1.0","info":{"title":"Hooks","version":"1"},"webhooks":{}}`,
+		},
+		{
+			name: "missing everything",
+			doc:  `{}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/openapi"},
+				{Severity: SeverityError, Pointer: "/info"},
+				{Severity: SeverityError, Pointer: "/paths"},
+			},
+		},
+		{
+			name: "unsupported version and info fields",
+			doc:  `{"openapi":"2.0","info":{"title":""},"paths":{}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/openapi"},
+				{Severity: SeverityError, Pointer: "/info/title"},
+				{Severity: SeverityError, Pointer: "/info/version"},
+			},
+		},
+		{
+			name: "operation problems",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{
+				"pets":{"get":{"operationId":"list","responses":{}}},
+				"/pets/{id}":{"get":{"operationId":"list"}}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/paths/~1pets~1{id}/get/responses"},
+				{Severity: SeverityWarning, Pointer: "/paths/~1pets~1{id}/get/parameters"},
+				{Severity: SeverityError, Pointer: "/paths/pets"},
+				{Severity: SeverityError, Pointer: "/paths/pets/get/operationId"},
+			},
+		},
+		{
+			name: "broken and external refs",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{"/a":{"get":{"responses":{
+				"200":{"$ref":"#/components/responses/Missing"},
+				"404":{"$ref":"common.json#/NotFound"}}}}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/paths/~1a/get/responses/200/$ref"},
+				{Severity: SeverityWarning, Pointer: "/paths/~1a/get/responses/404/$ref"},
+			},
+		},
+		{
+			name: "bad proxy config",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"ftp://files.example.com","authHeaders":{"Bad Header":"x"}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/baseURL"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/authHeaders/Bad Header"},
+			},
+		},
+		{
+			name: "relative base url",
+			doc:  `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},"x-proxy-config":{"baseURL":"/api"}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/baseURL"},
+			},
+		},
+		{
+			name: "environments",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"defaultEnvironment":"qa","environments":{
+				"dev":{"baseURL":"http://localhost:8081"},
+				"prod":{"baseURL":"api.example.com","authHeaders":{"Bad Header":"x"}}}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/environments/prod/baseURL"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/environments/prod/authHeaders/Bad Header"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/defaultEnvironment"},
+			},
+		},
+		{
+			name: "timeouts",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"http://localhost:8081","connectTimeout":"soon","responseTimeout":"-1s"}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/connectTimeout"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/responseTimeout"},
+			},
+		},
+		{
+			name: "retry policy",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"http://localhost:8081",
+				"retry":{"maxAttempts":20,"backoff":"fast","retryOn":[503,"504",700]}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/retry/maxAttempts"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/retry/backoff"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/retry/retryOn/1"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/retry/retryOn/2"},
+			},
+		},
+		{
+			name: "tls options",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"https://localhost:8443",
+				"tls":{"certFile":"client.pem","caFile":"","minVersion":"1.4","insecureSkipVerify":true}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/tls/caFile"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/tls"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/tls/minVersion"},
+				{Severity: SeverityWarning, Pointer: "/x-proxy-config/tls/insecureSkipVerify"},
+			},
+		},
+		{
+			name: "redirect hosts",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"https://api.example.com",
+				"redirectHosts":["auth.example.com","*.cdn.example.com","*","https://evil.example/",3]}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/redirectHosts/3"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/redirectHosts/4"},
+			},
+		},
+		{
+			name: "oauth2",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"https://api.example.com",
+				"oauth2":{"tokenURL":"/oauth/token","scopes":["read","bad scope"],"clientAuth":"jwt"}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/oauth2/tokenURL"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/oauth2/clientId"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/oauth2/scopes/1"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/oauth2/clientAuth"},
+			},
+		},
+		{
+			name: "aws signing",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"https://api.example.com",
+				"signing":{"type":"aws-sigv4","accessKeyId":"${env:AWS_ACCESS_KEY_ID}","region":"eu-west-1"}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/signing/secretAccessKey"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/signing/service"},
+			},
+		},
+		{
+			name: "hmac signing",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"https://api.example.com",
+				"signing":{"type":"hmac-sha256","secret":"x","header":"Bad Header","encoding":"base32"}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityError, Pointer: "/x-proxy-config/signing/header"},
+				{Severity: SeverityError, Pointer: "/x-proxy-config/signing/encoding"},
+			},
+		},
+		{
+			name: "custom signer",
+			doc: `{"openapi":"3.0.0","info":{"title":"T","version":"1"},"paths":{},
+				"x-proxy-config":{"baseURL":"https://api.example.com","signing":{"type":"acme-v2"}}}`,
+			want: []Diagnostic{
+				{Severity: SeverityWarning, Pointer: "/x-proxy-config/signing/type"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatalf("failed to unmarshal doc: %v", err)
+			}
+
+			got := validateSpec(doc)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d diagnostics, got %d: %+v", len(tt.want), len(got), got)
+			}
+
+			for i, want := range tt.want {
+				if got[i].Severity != want.Severity || got[i].Pointer != want.Pointer {
+					t.Errorf("diagnostic %d: expected %s at %s, got %s at %s (%s)",
+						i, want.Severity, want.Pointer, got[i].Severity, got[i].Pointer, got[i].Message)
+				}
+			}
+		})
+	}
+}
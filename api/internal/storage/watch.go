@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Watch polls the specs directory and reloads when spec files are added, changed or removed
+// Runs until ctx is cancelled. Load errors are logged once per distinct failure and the
+// last good version of the affected file keeps being served.
+func (s *FileSpecStore) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := s.fingerprint()
+		if err != nil {
+			if err.Error() != lastErr {
+				logger.Error("spec watch failed", "dir", s.specsDir, "error", err)
+				lastErr = err.Error()
+			}
+			continue
+		}
+		if current == s.loadedFingerprint() {
+			continue
+		}
+
+		if err := s.Reload(); err != nil {
+			logger.Error("spec reload failed, keeping last good versions", "dir", s.specsDir, "error", err)
+			lastErr = err.Error()
+			continue
+		}
+		lastErr = ""
+
+		names, _ := s.List()
+		logger.Info("specs reloaded", "dir", s.specsDir, "services", len(names))
+	}
+}
+
+// loadedFingerprint returns the directory fingerprint as of the last reload
+func (s *FileSpecStore) loadedFingerprint() string {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	return s.loaded
+}
+
+// fingerprint summarizes path, size and modification time of every spec and fragment file
+// Any difference between two fingerprints means the directory needs reloading
+func (s *FileSpecStore) fingerprint() (string, error) {
+	var lines []string
+
+	err := filepath.WalkDir(s.specsDir, func(path string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			// Removed mid-walk; the next poll will settle it
+			if path != s.specsDir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if dirEntry.IsDir() {
+			return nil
+		}
+		if _, ok := serviceNameFromFile(dirEntry.Name()); !ok {
+			return nil
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(s.specsDir, path)
+		lines = append(lines, fmt.Sprintf("%s|%d|%d", filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano()))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory: %w", err)
+	}
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\n"), nil
+}
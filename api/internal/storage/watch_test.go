@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileSpecStore_Watch(t *testing.T) {
+	tempDir := t.TempDir()
+	writeSpecFile(t, tempDir, "alpha.json", map[string]interface{}{"openapi": "3.0.0"})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		store.Watch(ctx, 10*time.Millisecond, slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	writeSpecFile(t, tempDir, "beta.json", map[string]interface{}{"openapi": "3.1.0"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		names, _ := store.List()
+		if strings.Join(names, ",") == "alpha,beta" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected watch to pick up beta.json, got %v", names)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Concurrent readers while the watcher swaps maps
+	for i := 0; i < 50; i++ {
+		if _, err := store.Get("alpha"); err != nil {
+			t.Fatalf("Get('alpha') failed: %v", err)
+		}
+	}
+}
+
+func TestFileSpecStore_FingerprintIncludesFragments(t *testing.T) {
+	tempDir := t.TempDir()
+	writeFiles(t, tempDir, map[string]string{
+		"svc/openapi.json":         `{"openapi":"3.0.0","paths":{}}`,
+		"svc/components/pets.json": `{"Pet":{"type":"object"}}`,
+	})
+
+	store, err := NewFileSpecStore(tempDir)
+	if err != nil {
+		t.Fatalf("NewFileSpecStore() failed: %v", err)
+	}
+
+	before, err := store.fingerprint()
+	if err != nil {
+		t.Fatalf("fingerprint() failed: %v", err)
+	}
+
+	writeFiles(t, tempDir, map[string]string{
+		"svc/components/pets.json": `{"Pet":{"type":"object","required":["id"]}}`,
+	})
+
+	after, err := store.fingerprint()
+	if err != nil {
+		t.Fatalf("fingerprint() failed: %v", err)
+	}
+	if before == after {
+		t.Error("expected fingerprint to change when a fragment file changes")
+	}
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlToJSON converts a YAML document to JSON, keeping mapping key order
+// so paths are listed in the UI in the order they were authored
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("empty YAML document")
+	}
+
+	var buf bytes.Buffer
+	if err := writeYAMLNode(&buf, doc.Content[0], 0, make(map[*yaml.Node][]byte)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const (
+	// maxYAMLDepth bounds nesting so a self-referencing anchor cannot recurse forever
+	maxYAMLDepth = 256
+
+	// maxYAMLJSONSize bounds the converted document; aliases fan out, so a few hundred bytes
+	// of nested anchors ("billion laughs") would otherwise expand until memory runs out
+	maxYAMLJSONSize = 32 << 20
+)
+
+// writeYAMLNode writes a single YAML node as JSON
+// expanded remembers the JSON of each anchor or merged value already written so repeats are copied, not reconverted
+func writeYAMLNode(buf *bytes.Buffer, node *yaml.Node, depth int, expanded map[*yaml.Node][]byte) error {
+	if depth > maxYAMLDepth {
+		return fmt.Errorf("YAML nesting too deep at line %d", node.Line)
+	}
+	if buf.Len() > maxYAMLJSONSize {
+		return errYAMLTooLarge(node)
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeYAMLNode(buf, node.Content[0], depth+1, expanded)
+
+	case yaml.AliasNode:
+		return writeSharedYAMLNode(buf, node.Alias, depth+1, expanded)
+
+	case yaml.MappingNode:
+		pairs, err := mappingPairs(node, depth)
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('{')
+		for i, pair := range pairs {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			// Non-string keys such as response codes (200:) become JSON strings
+			key, err := json.Marshal(pair.key.Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			write := writeYAMLNode
+			if pair.merged {
+				write = writeSharedYAMLNode
+			}
+			if err := write(buf, pair.value, depth+1, expanded); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeYAMLNode(buf, item, depth+1, expanded); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+
+	case yaml.ScalarNode:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("line %d: unsupported value %q: %w", node.Line, node.Value, err)
+		}
+		buf.Write(data)
+		return nil
+
+	default:
+		return fmt.Errorf("line %d: unsupported YAML node", node.Line)
+	}
+}
+
+// writeSharedYAMLNode writes a node that may appear many times in the output, through an
+// alias or a merge key, reusing its JSON once it has been converted
+func writeSharedYAMLNode(buf *bytes.Buffer, node *yaml.Node, depth int, expanded map[*yaml.Node][]byte) error {
+	if data, ok := expanded[node]; ok {
+		buf.Write(data)
+		if buf.Len() > maxYAMLJSONSize {
+			return errYAMLTooLarge(node)
+		}
+		return nil
+	}
+	start := buf.Len()
+	if err := writeYAMLNode(buf, node, depth, expanded); err != nil {
+		return err
+	}
+	expanded[node] = bytes.Clone(buf.Bytes()[start:])
+	return nil
+}
+
+// yamlPair is one key/value of a mapping; merged values were pulled in by a merge key
+type yamlPair struct {
+	key, value *yaml.Node
+	merged     bool
+}
+
+// mappingPairs lists a mapping's key/value nodes with merge keys (<<) expanded in place
+// A merge takes a mapping or a sequence of them; keys written in the mapping itself win over
+// merged ones, and earlier merged mappings win over later ones.
+func mappingPairs(node *yaml.Node, depth int) ([]yamlPair, error) {
+	if depth > maxYAMLDepth {
+		return nil, fmt.Errorf("YAML nesting too deep at line %d", node.Line)
+	}
+
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if !isMergeKey(node.Content[i]) {
+			explicit[node.Content[i].Value] = true
+		}
+	}
+
+	var pairs []yamlPair
+	merged := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !isMergeKey(key) {
+			pairs = append(pairs, yamlPair{key: key, value: value})
+			continue
+		}
+
+		sources := []*yaml.Node{value}
+		if resolveAlias(value).Kind == yaml.SequenceNode {
+			sources = resolveAlias(value).Content
+		}
+		for _, source := range sources {
+			source = resolveAlias(source)
+			if source.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("line %d: merge key << needs a mapping or a sequence of mappings", key.Line)
+			}
+			sourcePairs, err := mappingPairs(source, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			for _, pair := range sourcePairs {
+				if name := pair.key.Value; !explicit[name] && !merged[name] {
+					merged[name] = true
+					pairs = append(pairs, yamlPair{key: pair.key, value: pair.value, merged: true})
+				}
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// isMergeKey reports whether a mapping key is the YAML merge key <<
+func isMergeKey(key *yaml.Node) bool {
+	return key.Kind == yaml.ScalarNode && key.Tag == "!!merge"
+}
+
+// resolveAlias follows an alias to the node it names
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// errYAMLTooLarge reports a document whose aliases expand past maxYAMLJSONSize
+func errYAMLTooLarge(node *yaml.Node) error {
+	return fmt.Errorf("YAML document expands to more than %d MiB of JSON at line %d", maxYAMLJSONSize>>20, node.Line)
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYAMLToJSON_AliasExpansionLimit(t *testing.T) {
+	// Each level references the previous one ten times: 10^9 leaves from a few hundred bytes
+	var b strings.Builder
+	b.WriteString("a0: &a0 [lol, lol, lol, lol, lol, lol, lol, lol, lol, lol]\n")
+	for i := 1; i <= 9; i++ {
+		prev := fmt.Sprintf("*a%d", i-1)
+		fmt.Fprintf(&b, "a%d: &a%d [%s]\n", i, i, strings.TrimSuffix(strings.Repeat(prev+", ", 10), ", "))
+	}
+
+	start := time.Now()
+	_, err := yamlToJSON([]byte(b.String()))
+	if err == nil || !strings.Contains(err.Error(), "expands to more than") {
+		t.Fatalf("expected the expansion limit error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("conversion took %v before giving up", elapsed)
+	}
+}
+
+func TestYAMLToJSON_Aliases(t *testing.T) {
+	data, err := yamlToJSON([]byte("base: &base {type: string}\nname: *base\n"))
+	if err != nil {
+		t.Fatalf("yamlToJSON() failed: %v", err)
+	}
+	if want := `{"base":{"type":"string"},"name":{"type":"string"}}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestYAMLToJSON_MergeKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "single alias",
+			yaml: "base: &base {type: string, description: base}\nname: {<<: *base, description: x}\n",
+			want: `{"base":{"type":"string","description":"base"},"name":{"type":"string","description":"x"}}`,
+		},
+		{
+			name: "sequence of aliases",
+			yaml: "a: &a {x: 1, y: 1}\nb: &b {y: 2, z: 2}\nc:\n  <<: [*a, *b]\n  z: 3\n",
+			want: `{"a":{"x":1,"y":1},"b":{"y":2,"z":2},"c":{"x":1,"y":1,"z":3}}`,
+		},
+		{
+			name: "nested merges",
+			yaml: "a: &a {x: 1}\nb: &b {<<: *a, y: 2}\nc: {<<: *b}\n",
+			want: `{"a":{"x":1},"b":{"x":1,"y":2},"c":{"x":1,"y":2}}`,
+		},
+		{
+			name: "inline mapping",
+			yaml: "c: {<<: {x: 1}, y: 2}\n",
+			want: `{"c":{"x":1,"y":2}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := yamlToJSON([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("yamlToJSON() failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+
+	if _, err := yamlToJSON([]byte("a: &a 1\nc: {<<: *a}\n")); err == nil || !strings.Contains(err.Error(), "merge key") {
+		t.Errorf("expected a merge error for a scalar, got %v", err)
+	}
+}
+
+func TestYAMLToJSON_MergeExpansionLimit(t *testing.T) {
+	// Each level merges the previous one into ten keys, so merges fan out like aliases do
+	var b strings.Builder
+	b.WriteString("m0: &m0 {a: lol, b: lol, c: lol, d: lol, e: lol, f: lol, g: lol, h: lol, i: lol, j: lol}\n")
+	for i := 1; i <= 9; i++ {
+		fmt.Fprintf(&b, "m%d: &m%d {", i, i)
+		for k := 0; k < 10; k++ {
+			if k > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "k%d: {<<: *m%d}", k, i-1)
+		}
+		b.WriteString("}\n")
+	}
+
+	start := time.Now()
+	_, err := yamlToJSON([]byte(b.String()))
+	if err == nil || !strings.Contains(err.Error(), "expands to more than") {
+		t.Fatalf("expected the expansion limit error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("conversion took %v before giving up", elapsed)
+	}
+}
@@ -98,6 +98,50 @@ class ApiClientImpl implements ApiClient {
       );
     }
   }
+
+  /**
+   * Send a proxy request and receive the upstream response as-is, e.g. for
+   * large downloads or Server-Sent Events; read it with response.body
+   * POST /api/proxy/stream
+   */
+  async streamRequest(request: ProxyRequest, signal?: AbortSignal): Promise<Response> {
+    try {
+      const response = await fetch(`${this.config.baseURL}/api/proxy/stream`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(request),
+        signal,
+      });
+
+      // Upstream statuses pass through, so only problem documents are failures of the proxy itself
+      if (response.headers.get('Content-Type')?.startsWith('application/problem+json')) {
+        const errorText = await response.text();
+        throw new ApiError(
+          `Proxy request failed: ${response.statusText}`,
+          response.status,
+          errorText
+        );
+      }
+
+      return response;
+    } catch (error) {
+      if (error instanceof ApiError) throw error;
+      throw new ApiError(
+        error instanceof Error ? error.message : 'Proxy request failed'
+      );
+    }
+  }
+
+  /**
+   * URL to open a WebSocket to a service through the backend relay
+   * GET /ws/{service}/{path...}
+   */
+  webSocketURL(service: string, path: string): string {
+    const base = this.config.baseURL.replace(/^http/, 'ws');
+    return `${base}/ws/${encodeURIComponent(service)}${path.startsWith('/') ? path : `/${path}`}`;
+  }
 }
 
 // Export singleton instance
@@ -11,6 +11,8 @@ export interface ApiClient {
   getServices: () => Promise<string[]>;
   getSpec: (service: string) => Promise<OpenAPISpecWithProxy>;
   proxyRequest: <T = unknown>(request: ProxyRequest) => Promise<ProxyResponse<T>>;
+  streamRequest: (request: ProxyRequest, signal?: AbortSignal) => Promise<Response>;
+  webSocketURL: (service: string, path: string) => string;
 }
 
 // API error type
@@ -0,0 +1,73 @@
+import { useEffect, useMemo, useState } from 'react';
+import { Download } from 'lucide-react';
+
+interface BinaryBodyViewProps {
+  base64: string;
+  contentType: string;
+}
+
+// Decodes a base64 response body into bytes
+function decodeBase64(base64: string): Uint8Array<ArrayBuffer> {
+  const binary = atob(base64);
+  const bytes = new Uint8Array(binary.length);
+  for (let i = 0; i < binary.length; i++) {
+    bytes[i] = binary.charCodeAt(i);
+  }
+  return bytes;
+}
+
+function formatSize(bytes: number): string {
+  if (bytes < 1024) return `${bytes} B`;
+  if (bytes < 1024 * 1024) return `${(bytes / 1024).toFixed(1)} KB`;
+  return `${(bytes / (1024 * 1024)).toFixed(1)} MB`;
+}
+
+export function BinaryBodyView({ base64, contentType }: BinaryBodyViewProps) {
+  const bytes = useMemo(() => {
+    try {
+      return decodeBase64(base64);
+    } catch {
+      return null;
+    }
+  }, [base64]);
+
+  const mediaType = contentType.split(';')[0].trim().toLowerCase() || 'application/octet-stream';
+  const [url, setUrl] = useState<string | null>(null);
+
+  // The object URL backs both the image preview and the download link
+  useEffect(() => {
+    if (!bytes) return;
+    const objectUrl = URL.createObjectURL(new Blob([bytes], { type: mediaType }));
+    setUrl(objectUrl);
+    return () => URL.revokeObjectURL(objectUrl);
+  }, [bytes, mediaType]);
+
+  if (!bytes) {
+    return <div className="p-4 text-sm text-red-600 dark:text-red-400">The response body could not be decoded.</div>;
+  }
+
+  return (
+    <div className="p-4 space-y-4">
+      {url && mediaType.startsWith('image/') ? (
+        <img src={url} alt="Response body" className="max-h-[400px] max-w-full border border-border rounded-md" />
+      ) : (
+        <div className="text-sm text-muted-foreground">Binary body, not shown</div>
+      )}
+      <div className="flex items-center gap-4 text-sm">
+        <span className="font-mono text-muted-foreground">
+          {mediaType} · {formatSize(bytes.length)}
+        </span>
+        {url && (
+          <a
+            href={url}
+            download="response"
+            className="flex items-center gap-1.5 font-medium px-2 py-1 rounded-md text-muted-foreground hover:text-foreground hover:bg-muted/50 transition-colors"
+          >
+            <Download className="w-3.5 h-3.5" />
+            Download
+          </a>
+        )}
+      </div>
+    </div>
+  );
+}
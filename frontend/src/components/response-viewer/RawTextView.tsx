@@ -0,0 +1,32 @@
+import Editor from '@monaco-editor/react';
+import { useTheme } from '../../hooks/useTheme';
+
+interface RawTextViewProps {
+  text: string;
+}
+
+export function RawTextView({ text }: RawTextViewProps) {
+  const { resolvedTheme } = useTheme();
+
+  return (
+    <div className="border border-border rounded-md overflow-hidden">
+      <Editor
+        height="400px"
+        defaultLanguage="plaintext"
+        value={text}
+        options={{
+          readOnly: true,
+          minimap: { enabled: false },
+          fontSize: 13,
+          lineNumbers: 'on',
+          scrollBeyondLastLine: false,
+          wordWrap: 'on',
+          wrappingIndent: 'indent',
+          automaticLayout: true,
+          tabSize: 2,
+        }}
+        theme={resolvedTheme === 'dark' ? 'vs-dark' : 'vs-light'}
+      />
+    </div>
+  );
+}
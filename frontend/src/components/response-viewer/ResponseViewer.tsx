@@ -2,6 +2,8 @@ import { useState } from 'react';
 import type { ProxyResponse } from '../../types/request';
 import { PrettyDisplay } from './PrettyDisplay';
 import { RawJsonView } from './RawJsonView';
+import { RawTextView } from './RawTextView';
+import { BinaryBodyView } from './BinaryBodyView';
 import { Eye, Code, ArrowUpRight, Copy, Check } from 'lucide-react';
 import { cn } from '../../lib/utils';
 import { getHttpStatusBadgeStyles } from '../../lib/http-ui';
@@ -16,9 +18,14 @@ export function ResponseViewer({ response }: ResponseViewerProps) {
   const [copied, setCopied] = useState(false);
 
   const statusText = response.statusCode >= 200 && response.statusCode < 300 ? 'Success' : 'Error';
+  // Older backends leave bodyEncoding out and always send JSON
+  const bodyEncoding = response.bodyEncoding ?? 'json';
+  const bodyText = typeof response.body === 'string' ? response.body : '';
+  const contentType = Object.entries(response.headers)
+    .find(([key]) => key.toLowerCase() === 'content-type')?.[1]?.[0] ?? '';
 
   const handleCopy = () => {
-    navigator.clipboard.writeText(JSON.stringify(response.body, null, 2));
+    navigator.clipboard.writeText(bodyEncoding === 'json' ? JSON.stringify(response.body, null, 2) : bodyText);
     setCopied(true);
     setTimeout(() => setCopied(false), 2000);
   };
@@ -82,36 +89,44 @@ export function ResponseViewer({ response }: ResponseViewerProps) {
 
       {/* View Mode Tabs */}
       <div className="space-y-4">
-        <div className="flex items-center border-b border-border">
-          <button
-            onClick={() => setViewMode('pretty')}
-            className={cn(
-              "px-4 py-2 text-sm font-medium border-b-2 transition-colors flex items-center gap-2",
-              viewMode === 'pretty'
-                ? "border-primary text-primary"
-                : "border-transparent text-muted-foreground hover:text-foreground hover:border-border"
-            )}
-          >
-            <Code className="w-4 h-4" />
-            Pretty
-          </button>
-          <button
-            onClick={() => setViewMode('raw')}
-            className={cn(
-              "px-4 py-2 text-sm font-medium border-b-2 transition-colors flex items-center gap-2",
-              viewMode === 'raw'
-                ? "border-primary text-primary"
-                : "border-transparent text-muted-foreground hover:text-foreground hover:border-border"
-            )}
-          >
-            <ArrowUpRight className="w-4 h-4" />
-            Raw JSON
-          </button>
-        </div>
+        {bodyEncoding === 'json' && (
+          <div className="flex items-center border-b border-border">
+            <button
+              onClick={() => setViewMode('pretty')}
+              className={cn(
+                "px-4 py-2 text-sm font-medium border-b-2 transition-colors flex items-center gap-2",
+                viewMode === 'pretty'
+                  ? "border-primary text-primary"
+                  : "border-transparent text-muted-foreground hover:text-foreground hover:border-border"
+              )}
+            >
+              <Code className="w-4 h-4" />
+              Pretty
+            </button>
+            <button
+              onClick={() => setViewMode('raw')}
+              className={cn(
+                "px-4 py-2 text-sm font-medium border-b-2 transition-colors flex items-center gap-2",
+                viewMode === 'raw'
+                  ? "border-primary text-primary"
+                  : "border-transparent text-muted-foreground hover:text-foreground hover:border-border"
+              )}
+            >
+              <ArrowUpRight className="w-4 h-4" />
+              Raw JSON
+            </button>
+          </div>
+        )}
 
         {/* Response Body */}
         <div className="bg-card border border-border rounded-lg overflow-hidden min-h-[300px] shadow-sm">
-          {viewMode === 'pretty' ? (
+          {bodyEncoding === 'text' ? (
+            <div className="p-4">
+              <RawTextView text={bodyText} />
+            </div>
+          ) : bodyEncoding === 'base64' ? (
+            <BinaryBodyView base64={bodyText} contentType={contentType} />
+          ) : viewMode === 'pretty' ? (
             <PrettyDisplay data={response.body} />
           ) : (
             <div className="p-4">
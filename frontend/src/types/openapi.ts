@@ -10,9 +10,60 @@ export type RequestBodyObject = OpenAPIV3.RequestBodyObject;
 export type ResponseObject = OpenAPIV3.ResponseObject;
 
 // Custom type for proxy configuration in specs
+// The backend serves a redacted copy: header values never reach the browser
 export interface ProxyConfig {
+  baseURL?: string;
+  authHeaderNames?: string[];
+  environments?: ProxyEnvironment[];
+  defaultEnvironment?: string;
+  connectTimeout?: string; // Go duration, e.g. 5s
+  responseTimeout?: string;
+  retry?: RetryPolicy;
+  tls?: ProxyTLS;
+  redirectHosts?: string[]; // Hosts redirects may lead to besides the service's own
+  oauth2?: ProxyOAuth2;
+  signing?: ProxySigning;
+}
+
+// Request signer applied by the backend; keys and secrets are never sent to the browser
+export interface ProxySigning {
+  type: 'aws-sigv4' | 'hmac-sha256' | 'basic' | string;
+  region?: string; // aws-sigv4
+  service?: string; // aws-sigv4
+  header?: string; // hmac-sha256 signature header
+}
+
+// OAuth2 token acquisition done by the backend; credentials and tokens are never sent to the browser
+export interface ProxyOAuth2 {
+  tokenURL: string;
+  grantType: 'client_credentials' | 'refresh_token';
+  scopes?: string[];
+}
+
+// TLS settings for reaching the service, without file locations
+export interface ProxyTLS {
+  clientCertificate?: boolean; // mTLS client certificate configured
+  customCA?: boolean; // Private CA bundle trusted in addition to system roots
+  minVersion?: string; // 1.0, 1.1, 1.2 or 1.3
+  insecureSkipVerify?: boolean;
+}
+
+// How the proxy retries failed calls; only idempotent methods unless allowNonIdempotent
+export interface RetryPolicy {
+  maxAttempts: number; // Including the first call
+  backoff?: string; // Go duration, doubled after each retry
+  maxBackoff?: string;
+  retryOn?: number[]; // Defaults to 502, 503 and 504
+  networkErrors?: boolean;
+  allowNonIdempotent?: boolean;
+}
+
+// Named deployment of a service (dev, staging, prod), without secrets
+export interface ProxyEnvironment {
+  name: string;
   baseURL: string;
-  authHeaders?: Record<string, string>;
+  authHeaderNames?: string[];
+  default?: boolean;
 }
 
 // Extended OpenAPI spec with proxy config
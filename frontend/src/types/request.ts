@@ -2,18 +2,106 @@
 
 export type HttpMethod = 'GET' | 'POST' | 'PUT' | 'PATCH' | 'DELETE' | 'HEAD' | 'OPTIONS';
 
+// How the backend encodes the request body; inferred from the spec when omitted
+export type BodyMode = 'json' | 'form' | 'multipart' | 'text' | 'binary';
+
+// Form field or file (base64 data) for form and multipart bodies
+export interface BodyPart {
+  name: string;
+  value?: string;
+  filename?: string;
+  contentType?: string;
+  data?: string;
+}
+
 export interface ProxyRequest {
   service: string;
   method: HttpMethod;
   path: string;
   headers?: Record<string, string>;
   body?: unknown;
+  bodyMode?: BodyMode;
+  parts?: BodyPart[];
+  environment?: string; // One of the service's environments; omitted uses the default
+  server?: number; // Index into the spec's servers[], used instead of x-proxy-config.baseURL
+  serverVariables?: Record<string, string>; // Values for {variables} in the chosen server URL
+  validate?: boolean; // Check the request against the spec before forwarding
+  force?: boolean; // Skip validation even when the server validates every request
+  timeout?: string; // Overrides the service's response timeout, e.g. 10s; capped by the server
+}
+
+// A single way a request or response fails to match the spec
+export interface ValidationViolation {
+  pointer: string; // e.g. /query/limit or /body/name
+  message: string;
+}
+
+// Stable codes for proxy failures, one per failure class
+export type ProxyErrorCode =
+  | 'invalid_request'
+  | 'request_body_too_large'
+  | 'request_validation_failed'
+  | 'service_not_found'
+  | 'target_denied'
+  | 'token_request_failed'
+  | 'upstream_timeout'
+  | 'request_canceled'
+  | 'upstream_dns_failure'
+  | 'upstream_connection_refused'
+  | 'upstream_unreachable'
+  | 'upstream_response_unreadable'
+  | 'proxy_error';
+
+// RFC 7807 body (application/problem+json) returned instead of a ProxyResponse on failure
+export interface ProblemDetails {
+  type: string; // urn:playground:problem:<code>
+  title: string;
+  status: number;
+  detail?: string;
+  code: ProxyErrorCode;
+  violations?: ValidationViolation[]; // Set for request_validation_failed (status 422)
 }
 
+// How the backend packed the upstream body into the JSON envelope:
+// json is the document itself, text is a string, base64 is encoded bytes
+export type BodyEncoding = 'json' | 'text' | 'base64';
+
 export interface ProxyResponse<T = unknown> {
   statusCode: number;
   headers: Record<string, string[]>;
   body: T;
+  bodyEncoding?: BodyEncoding;
+  truncated?: boolean; // Body was cut off at the backend's PROXY_MAX_BODY_SIZE
+  validation?: ResponseValidation; // Omitted when no spec operation matches the request
+  timings?: ProxyTimings; // Of the last attempt
+  attempts?: ProxyAttempt[]; // Present when the service has a retry policy
+}
+
+// One call to the backend made under a retry policy
+export interface ProxyAttempt {
+  statusCode?: number; // Omitted when no response arrived
+  error?: string;
+  duration: number; // Milliseconds
+}
+
+// Where the time of a proxied request went, in milliseconds
+// dnsLookup, tcpConnect and tlsHandshake are 0 when a pooled connection was reused
+export interface ProxyTimings {
+  dnsLookup: number;
+  tcpConnect: number;
+  tlsHandshake: number;
+  timeToFirstByte: number;
+  download: number;
+  total: number;
+  remoteAddr?: string;
+  connectionReused: boolean;
+}
+
+// How the upstream response compares to the operation's documented responses
+export interface ResponseValidation {
+  operation: string; // e.g. GET /pets/{id}
+  valid: boolean;
+  violations: ValidationViolation[];
 }
 
 // Request form state